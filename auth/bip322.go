@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+const (
+	sigHashDefault byte = 0x00
+	sigHashAll     byte = 0x01
+)
+
+// VerifyBIP322Signature verifies a BIP-322 signature over a message by a bip122
+// account. Simple signatures, a serialized witness stack, are supported for
+// P2WPKH and P2TR key path spends. Legacy 65 byte signmessage signatures, as
+// described in BIP-137, are accepted for P2PKH, P2SH-P2WPKH and P2WPKH
+// addresses.
+func VerifyBIP322Signature(account blockchain.AccountId, message, signature []byte) error {
+	address, err := account.BitcoinAddress()
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, errors.ErrUnsupported)
+	}
+
+	if len(signature) == 65 && signature[0] >= 27 && signature[0] <= 42 {
+		return verifyLegacyBitcoinSignature(address, message, signature)
+	}
+
+	witness, err := parseWitness(signature)
+	if err != nil {
+		return fmt.Errorf("invalid bip322 signature: %v: %w", err, errors.ErrUnauthorised)
+	}
+
+	switch address.ScriptType {
+	case blockchain.BitcoinP2WPKH:
+		return verifyP2WPKHSignature(address, message, witness)
+	case blockchain.BitcoinP2TR:
+		return verifyP2TRSignature(address, message, witness)
+	default:
+		return fmt.Errorf("bip322 signatures for %s addresses are not supported: %w", address.ScriptType, errors.ErrUnsupported)
+	}
+}
+
+// verifyLegacyBitcoinSignature verifies a signmessage signature, whose header
+// byte is 27 + recovery id, plus 4 for compressed P2PKH keys, 8 for
+// P2SH-P2WPKH keys or 12 for P2WPKH keys
+func verifyLegacyBitcoinSignature(address blockchain.BitcoinAddress, message, signature []byte) error {
+	header := signature[0]
+	compressed := header >= 31
+	recoveryId := (header - 27) % 4
+
+	compact := append([]byte{27 + recoveryId}, signature[1:]...)
+	if compressed {
+		compact[0] += 4
+	}
+
+	key, _, err := ecdsa.RecoverCompact(compact, bitcoinMessageHash(message))
+	if err != nil {
+		return fmt.Errorf("invalid bitcoin message signature: %v: %w", err, errors.ErrUnauthorised)
+	}
+
+	keyHash := hashes.Hash160(key.SerializeCompressed())
+	if !compressed {
+		keyHash = hashes.Hash160(key.SerializeUncompressed())
+	}
+
+	var program []byte
+	switch address.ScriptType {
+	case blockchain.BitcoinP2PKH:
+		program = keyHash
+	case blockchain.BitcoinP2WPKH:
+		if compressed {
+			program = keyHash
+		}
+	case blockchain.BitcoinP2SH:
+		// P2SH-P2WPKH commits to the hash of the witness program script
+		if compressed {
+			program = hashes.Hash160(append([]byte{0x00, 0x14}, keyHash...))
+		}
+	}
+
+	if program == nil || !bytes.Equal(program, address.Program) {
+		return fmt.Errorf("bitcoin message signature is not by the address: %w", errors.ErrUnauthorised)
+	}
+
+	return nil
+}
+
+// bitcoinMessageHash returns the hash signed by signmessage, the double SHA-256
+// of the message with the Bitcoin Signed Message prefix
+func bitcoinMessageHash(message []byte) []byte {
+	const prefix = "Bitcoin Signed Message:\n"
+
+	var buf bytes.Buffer
+	writeVarBytes(&buf, []byte(prefix))
+	writeVarBytes(&buf, message)
+	return doubleSHA256(buf.Bytes())
+}
+
+func verifyP2WPKHSignature(address blockchain.BitcoinAddress, message []byte, witness [][]byte) error {
+	if len(witness) != 2 || len(witness[0]) == 0 {
+		return fmt.Errorf("p2wpkh witness must hold a signature and public key: %w", errors.ErrUnauthorised)
+	}
+
+	sig, publicKey := witness[0], witness[1]
+	if !bytes.Equal(hashes.Hash160(publicKey), address.Program) {
+		return fmt.Errorf("bip322 signature is not by the address: %w", errors.ErrUnauthorised)
+	}
+
+	key, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil || len(publicKey) != secp256k1.PubKeyBytesLenCompressed {
+		return fmt.Errorf("p2wpkh witness must hold a compressed public key: %w", errors.ErrUnauthorised)
+	}
+
+	if sig[len(sig)-1] != sigHashAll {
+		return fmt.Errorf("bip322 signature must use SIGHASH_ALL: %w", errors.ErrUnauthorised)
+	}
+
+	parsed, err := ecdsa.ParseDERSignature(sig[:len(sig)-1])
+	if err != nil {
+		return fmt.Errorf("invalid bip322 signature: %v: %w", err, errors.ErrUnauthorised)
+	}
+
+	toSpend := bip322ToSpend(message, witnessScript(0, address.Program))
+	if !parsed.Verify(bip143SigHash(toSpend, address.Program), key) {
+		return fmt.Errorf("bip322 signature is not by the address: %w", errors.ErrUnauthorised)
+	}
+
+	return nil
+}
+
+func verifyP2TRSignature(address blockchain.BitcoinAddress, message []byte, witness [][]byte) error {
+	if len(witness) != 1 {
+		return fmt.Errorf("p2tr key path witness must hold a single signature: %w", errors.ErrUnauthorised)
+	}
+
+	sig, hashType := witness[0], sigHashDefault
+	switch {
+	case len(sig) == 65 && sig[64] == sigHashAll:
+		sig, hashType = sig[:64], sigHashAll
+	case len(sig) != 64:
+		return fmt.Errorf("bip322 signature must use SIGHASH_DEFAULT or SIGHASH_ALL: %w", errors.ErrUnauthorised)
+	}
+
+	toSpend := bip322ToSpend(message, witnessScript(1, address.Program))
+	hash := bip341SigHash(toSpend, witnessScript(1, address.Program), hashType)
+	if !verifySchnorr(address.Program, hash, sig) {
+		return fmt.Errorf("bip322 signature is not by the address: %w", errors.ErrUnauthorised)
+	}
+
+	return nil
+}
+
+// witnessScript returns the output script of a witness program
+func witnessScript(version byte, program []byte) []byte {
+	opcode := version
+	if version > 0 {
+		// OP_1 to OP_16
+		opcode = 0x50 + version
+	}
+	return append([]byte{opcode, byte(len(program))}, program...)
+}
+
+// bip322ToSpend returns the txid of the virtual to_spend transaction, which
+// commits to the message and pays to the address being proven
+func bip322ToSpend(message, scriptPubKey []byte) []byte {
+	messageHash := hashes.TaggedHash("BIP0322-signed-message", message)
+
+	var tx bytes.Buffer
+	tx.Write(make([]byte, 4))
+	tx.WriteByte(1)
+	// The input spends the null outpoint with OP_0 PUSH32 message_hash
+	tx.Write(make([]byte, 32))
+	tx.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	writeVarBytes(&tx, append([]byte{0x00, 0x20}, messageHash...))
+	tx.Write(make([]byte, 4))
+	tx.WriteByte(1)
+	tx.Write(make([]byte, 8))
+	writeVarBytes(&tx, scriptPubKey)
+	tx.Write(make([]byte, 4))
+
+	return doubleSHA256(tx.Bytes())
+}
+
+// bip322ToSignOutputs returns the serialized outputs of the virtual to_sign
+// transaction, a single zero value OP_RETURN output
+func bip322ToSignOutputs() []byte {
+	return append(make([]byte, 8), 0x01, 0x6a)
+}
+
+// bip322ToSignOutpoint returns the outpoint spent by the to_sign transaction
+func bip322ToSignOutpoint(toSpend []byte) []byte {
+	return append(append([]byte{}, toSpend...), 0, 0, 0, 0)
+}
+
+// bip143SigHash returns the BIP-143 SIGHASH_ALL signature hash of the P2WPKH
+// input of the to_sign transaction. The version, sequence, amount and lock
+// time of the transaction are all zero.
+func bip143SigHash(toSpend, keyHash []byte) []byte {
+	outpoint := bip322ToSignOutpoint(toSpend)
+	zero := make([]byte, 4)
+
+	var preimage bytes.Buffer
+	preimage.Write(zero)
+	preimage.Write(doubleSHA256(outpoint))
+	preimage.Write(doubleSHA256(zero))
+	preimage.Write(outpoint)
+	// The script code of P2WPKH is the P2PKH script of the key hash
+	writeVarBytes(&preimage, append(append([]byte{0x76, 0xa9, 0x14}, keyHash...), 0x88, 0xac))
+	preimage.Write(make([]byte, 8))
+	preimage.Write(zero)
+	preimage.Write(doubleSHA256(bip322ToSignOutputs()))
+	preimage.Write(zero)
+	preimage.Write(binary.LittleEndian.AppendUint32(nil, uint32(sigHashAll)))
+
+	return doubleSHA256(preimage.Bytes())
+}
+
+// bip341SigHash returns the BIP-341 key path signature hash of the input of the
+// to_sign transaction for SIGHASH_DEFAULT or SIGHASH_ALL
+func bip341SigHash(toSpend, scriptPubKey []byte, hashType byte) []byte {
+	zero := make([]byte, 4)
+
+	var scriptPubKeys bytes.Buffer
+	writeVarBytes(&scriptPubKeys, scriptPubKey)
+
+	var msg bytes.Buffer
+	// The epoch precedes the signature message
+	msg.WriteByte(0x00)
+	msg.WriteByte(hashType)
+	msg.Write(zero)
+	msg.Write(zero)
+	msg.Write(sha256Sum(bip322ToSignOutpoint(toSpend)))
+	msg.Write(sha256Sum(make([]byte, 8)))
+	msg.Write(sha256Sum(scriptPubKeys.Bytes()))
+	msg.Write(sha256Sum(zero))
+	msg.Write(sha256Sum(bip322ToSignOutputs()))
+	// Key path spend without an annex
+	msg.WriteByte(0x00)
+	msg.Write(zero)
+
+	return hashes.TaggedHash("TapSighash", msg.Bytes())
+}
+
+// verifySchnorr verifies a BIP-340 signature by an x-only public key
+func verifySchnorr(publicKey, hash, sig []byte) bool {
+	if len(publicKey) != 32 || len(sig) != 64 {
+		return false
+	}
+
+	key, err := secp256k1.ParsePubKey(append([]byte{secp256k1.PubKeyFormatCompressedEven}, publicKey...))
+	if err != nil {
+		return false
+	}
+
+	var r secp256k1.FieldVal
+	if overflow := r.SetByteSlice(sig[:32]); overflow {
+		return false
+	}
+
+	var s secp256k1.ModNScalar
+	if overflow := s.SetByteSlice(sig[32:]); overflow {
+		return false
+	}
+
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(hashes.TaggedHash("BIP0340/challenge", sig[:32], publicKey, hash))
+
+	// R = s*G - e*P must have an even y coordinate and x equal to r
+	var p, sG, eP, rPoint secp256k1.JacobianPoint
+	key.AsJacobian(&p)
+	secp256k1.ScalarBaseMultNonConst(&s, &sG)
+	secp256k1.ScalarMultNonConst(e.Negate(), &p, &eP)
+	secp256k1.AddNonConst(&sG, &eP, &rPoint)
+
+	if (rPoint.X.IsZero() && rPoint.Y.IsZero()) || rPoint.Z.IsZero() {
+		return false
+	}
+	rPoint.ToAffine()
+
+	return !rPoint.Y.IsOdd() && rPoint.X.Equals(&r)
+}
+
+// parseWitness parses a consensus serialized witness stack
+func parseWitness(b []byte) ([][]byte, error) {
+	r := bytes.NewReader(b)
+	count, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if count > uint64(len(b)) {
+		return nil, fmt.Errorf("witness stack has too many items")
+	}
+
+	witness := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		size, err := readVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > uint64(r.Len()) {
+			return nil, fmt.Errorf("witness item exceeds the signature")
+		}
+
+		item := make([]byte, size)
+		if _, err := io.ReadFull(r, item); err != nil {
+			return nil, err
+		}
+		witness = append(witness, item)
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("witness stack has trailing bytes")
+	}
+
+	return witness, nil
+}
+
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int
+	switch prefix {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(prefix), nil
+	}
+
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(r, b[:size]); err != nil {
+		return 0, fmt.Errorf("truncated varint")
+	}
+
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+func writeVarBytes(buf *bytes.Buffer, b []byte) {
+	switch n := len(b); {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0xfd)
+		buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(0xfe)
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(n)))
+	}
+	buf.Write(b)
+}
+
+func sha256Sum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+func doubleSHA256(b []byte) []byte {
+	return sha256Sum(sha256Sum(b))
+}
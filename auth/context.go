@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/offblocks/offblocks-common/blockchain"
 	"github.com/offblocks/offblocks-common/errors"
 	"github.com/offblocks/offblocks-common/types"
 )
@@ -11,7 +12,8 @@ import (
 type key string
 
 const (
-	ClientIdKey key = "client-id"
+	ClientIdKey  key = "client-id"
+	AccountIdKey key = "account-id"
 )
 
 type Context struct {
@@ -29,3 +31,18 @@ func (c Context) ClientId() (types.UUID, error) {
 func WithClientId(ctx context.Context, clientId types.UUID) Context {
 	return Context{context.WithValue(ctx, ClientIdKey, clientId)}
 }
+
+// AccountId returns the blockchain account the session signed in with
+func (c Context) AccountId() (blockchain.AccountId, error) {
+	accountId := c.Value(AccountIdKey)
+	if accountId == nil {
+		return blockchain.AccountId{}, errors.ErrUnauthorised
+	}
+	return accountId.(blockchain.AccountId), nil
+}
+
+// WithAccountId returns a context for a session signed in with a blockchain
+// account, such as one verified by VerifySIWxMessage
+func WithAccountId(ctx context.Context, accountId blockchain.AccountId) Context {
+	return Context{context.WithValue(ctx, AccountIdKey, accountId)}
+}
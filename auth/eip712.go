@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+// TypedDataField is a member of an EIP-712 struct type
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is EIP-712 structured data in the eth_signTypedData_v4 JSON form.
+// Types must include the EIP712Domain type describing the domain.
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]any              `json:"domain"`
+	Message     map[string]any              `json:"message"`
+}
+
+const eip712DomainType = "EIP712Domain"
+
+var (
+	eip712IntegerRegex = regexp.MustCompile(`^(u?)int(\d*)$`)
+	eip712BytesRegex   = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+// VerifyTypedDataSignature verifies an EIP-712 eth_signTypedData_v4 signature
+// over typed data by an eip155 account. If the domain has a chainId it must be
+// the chain of the account, so signatures cannot be replayed on other chains.
+func VerifyTypedDataSignature(account blockchain.AccountId, data TypedData, signature []byte) error {
+	if account.ChainId.Namespace != "eip155" {
+		return fmt.Errorf("account %s is not an eip155 account: %w", account, errors.ErrUnsupported)
+	}
+
+	if chainId, ok := data.Domain["chainId"]; ok {
+		n, err := parseTypedDataInteger(chainId)
+		if err != nil {
+			return fmt.Errorf("invalid eip712 domain chainId: %v: %w", err, errors.ErrInvalid)
+		}
+
+		if n.String() != account.ChainId.Reference {
+			return fmt.Errorf("eip712 domain is for chain %s, not %s: %w", n, account.ChainId, errors.ErrUnauthorised)
+		}
+	}
+
+	hash, err := data.Hash()
+	if err != nil {
+		return err
+	}
+
+	return verifyEIP155Signature(account, hash, signature)
+}
+
+// Hash returns the EIP-712 signing hash of the typed data,
+// keccak256(0x19 0x01 || domainSeparator || hashStruct(message))
+func (d TypedData) Hash() ([]byte, error) {
+	if _, ok := d.Types[eip712DomainType]; !ok {
+		return nil, fmt.Errorf("eip712 types do not include %s: %w", eip712DomainType, errors.ErrInvalid)
+	}
+
+	domainSeparator, err := d.hashStruct(eip712DomainType, d.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Signing only the domain omits the message hash
+	if d.PrimaryType == eip712DomainType {
+		return hashes.Keccak256([]byte{0x19, 0x01}, domainSeparator), nil
+	}
+
+	messageHash, err := d.hashStruct(d.PrimaryType, d.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes.Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+func (d TypedData) hashStruct(typeName string, data map[string]any) ([]byte, error) {
+	fields, ok := d.Types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("unknown eip712 type %s: %w", typeName, errors.ErrInvalid)
+	}
+
+	encodedType, err := d.encodeType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := [][]byte{hashes.Keccak256([]byte(encodedType))}
+	for _, field := range fields {
+		// Missing struct values are encoded as zero, as MetaMask does for
+		// eth_signTypedData_v4, but other missing values are rejected
+		value, ok := data[field.Name]
+		if _, isStruct := d.Types[field.Type]; !ok && !isStruct {
+			return nil, fmt.Errorf("eip712 %s is missing field %s: %w", typeName, field.Name, errors.ErrInvalid)
+		}
+
+		b, err := d.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("eip712 %s.%s: %w", typeName, field.Name, err)
+		}
+		encoded = append(encoded, b)
+	}
+
+	return hashes.Keccak256(encoded...), nil
+}
+
+// encodeType returns the encoding of a struct type followed by the types it
+// references sorted by name, e.g. Mail(Person from,Person to)Person(string name)
+func (d TypedData) encodeType(typeName string) (string, error) {
+	deps := map[string]bool{}
+	if err := d.findDependencies(typeName, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typeName)
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range append([]string{typeName}, names...) {
+		sb.WriteString(name)
+		sb.WriteString("(")
+		for i, field := range d.Types[name] {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(field.Type)
+			sb.WriteString(" ")
+			sb.WriteString(field.Name)
+		}
+		sb.WriteString(")")
+	}
+
+	return sb.String(), nil
+}
+
+func (d TypedData) findDependencies(typeName string, deps map[string]bool) error {
+	if deps[typeName] {
+		return nil
+	}
+
+	fields, ok := d.Types[typeName]
+	if !ok {
+		return fmt.Errorf("unknown eip712 type %s: %w", typeName, errors.ErrInvalid)
+	}
+	deps[typeName] = true
+
+	for _, field := range fields {
+		base := arrayBaseType(field.Type)
+		if _, ok := d.Types[base]; ok {
+			if err := d.findDependencies(base, deps); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func arrayBaseType(typeName string) string {
+	if i := strings.Index(typeName, "["); i >= 0 {
+		return typeName[:i]
+	}
+	return typeName
+}
+
+// encodeValue encodes a value of a type into 32 bytes, hashing dynamic values,
+// arrays and structs
+func (d TypedData) encodeValue(typeName string, value any) ([]byte, error) {
+	if strings.HasSuffix(typeName, "]") {
+		i := strings.LastIndex(typeName, "[")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid eip712 array type %s: %w", typeName, errors.ErrInvalid)
+		}
+
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("eip712 %s value must be an array: %w", typeName, errors.ErrInvalid)
+		}
+
+		if size := typeName[i+1 : len(typeName)-1]; size != "" && size != strconv.Itoa(len(items)) {
+			return nil, fmt.Errorf("eip712 %s value has %d items: %w", typeName, len(items), errors.ErrInvalid)
+		}
+
+		encoded := make([][]byte, 0, len(items))
+		for _, item := range items {
+			b, err := d.encodeValue(typeName[:i], item)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, b)
+		}
+
+		return hashes.Keccak256(encoded...), nil
+	}
+
+	if _, ok := d.Types[typeName]; ok {
+		if value == nil {
+			return make([]byte, 32), nil
+		}
+
+		data, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("eip712 %s value must be an object: %w", typeName, errors.ErrInvalid)
+		}
+
+		return d.hashStruct(typeName, data)
+	}
+
+	switch typeName {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("eip712 string value must be a string: %w", errors.ErrInvalid)
+		}
+		return hashes.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := parseTypedDataBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return hashes.Keccak256(b), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("eip712 bool value must be a boolean: %w", errors.ErrInvalid)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case "address":
+		b, err := parseTypedDataBytes(value)
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("eip712 address value must be 20 bytes of hex: %w", errors.ErrInvalid)
+		}
+		return leftPad(b), nil
+	}
+
+	if match := eip712BytesRegex.FindStringSubmatch(typeName); match != nil {
+		size, _ := strconv.Atoi(match[1])
+		b, err := parseTypedDataBytes(value)
+		if err != nil || size < 1 || size > 32 || len(b) > size {
+			return nil, fmt.Errorf("invalid eip712 %s value: %w", typeName, errors.ErrInvalid)
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	}
+
+	if match := eip712IntegerRegex.FindStringSubmatch(typeName); match != nil {
+		return encodeTypedDataInteger(typeName, match[1] == "u", match[2], value)
+	}
+
+	return nil, fmt.Errorf("unknown eip712 type %s: %w", typeName, errors.ErrInvalid)
+}
+
+// encodeTypedDataInteger encodes a uintN or intN value as a 256 bit two's
+// complement integer after checking it fits in N bits
+func encodeTypedDataInteger(typeName string, unsigned bool, bits string, value any) ([]byte, error) {
+	size := 256
+	if bits != "" {
+		size, _ = strconv.Atoi(bits)
+		if size < 8 || size > 256 || size%8 != 0 {
+			return nil, fmt.Errorf("unknown eip712 type %s: %w", typeName, errors.ErrInvalid)
+		}
+	}
+
+	n, err := parseTypedDataInteger(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid eip712 %s value: %v: %w", typeName, err, errors.ErrInvalid)
+	}
+
+	var min, max *big.Int
+	if unsigned {
+		min = big.NewInt(0)
+		max = new(big.Int).Lsh(big.NewInt(1), uint(size))
+	} else {
+		max = new(big.Int).Lsh(big.NewInt(1), uint(size-1))
+		min = new(big.Int).Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("eip712 %s value %s is out of range: %w", typeName, n, errors.ErrInvalid)
+	}
+
+	if n.Sign() < 0 {
+		n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+
+	return n.FillBytes(make([]byte, 32)), nil
+}
+
+// parseTypedDataInteger parses an integer from a JSON number or a decimal or
+// 0x prefixed hex string
+func parseTypedDataInteger(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return nil, fmt.Errorf("%v is not an exact integer", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case json.Number:
+		return parseTypedDataInteger(v.String())
+	case string:
+		n, ok := new(big.Int), false
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			n, ok = n.SetString(v[2:], 16)
+		} else {
+			n, ok = n.SetString(v, 10)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%T is not an integer", value)
+	}
+}
+
+func parseTypedDataBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") && !strings.HasPrefix(v, "0X") {
+			return nil, fmt.Errorf("eip712 bytes value must be 0x prefixed hex: %w", errors.ErrInvalid)
+		}
+		b, err := hex.DecodeString(v[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid eip712 bytes value: %v: %w", err, errors.ErrInvalid)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("eip712 bytes value must be a hex string: %w", errors.ErrInvalid)
+	}
+}
+
+func leftPad(b []byte) []byte {
+	word := make([]byte, 32)
+	copy(word[32-len(b):], b)
+	return word
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strconv"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+// VerifyMessageSignature verifies that a signature over a message was produced
+// by the key of an account, using the message signing scheme of the account's
+// namespace: EIP-191 personal_sign for eip155, ed25519 for solana and BIP-322
+// for bip122. Signatures are raw bytes, decoded from the hex, base58 or base64
+// form the wallet returned. It returns an error wrapping
+// errors.ErrUnauthorised if the signature does not prove ownership of the
+// account.
+func VerifyMessageSignature(account blockchain.AccountId, message, signature []byte) error {
+	switch account.ChainId.Namespace {
+	case "eip155":
+		return VerifyPersonalSignature(account, message, signature)
+	case "solana":
+		return VerifySolanaSignature(account, message, signature)
+	case "bip122":
+		return VerifyBIP322Signature(account, message, signature)
+	default:
+		return fmt.Errorf("message signatures are not supported in namespace %s: %w", account.ChainId.Namespace, errors.ErrUnsupported)
+	}
+}
+
+// VerifyPersonalSignature verifies an EIP-191 personal_sign signature over a
+// message by an eip155 account. Only externally owned accounts can be verified
+// offline, contract wallets using ERC-1271 are not supported.
+func VerifyPersonalSignature(account blockchain.AccountId, message, signature []byte) error {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return verifyEIP155Signature(account, hashes.Keccak256([]byte(prefix), message), signature)
+}
+
+// verifyEIP155Signature recovers the signer of a 65 byte r || s || v signature
+// over a hash and checks that it is the account
+func verifyEIP155Signature(account blockchain.AccountId, hash, signature []byte) error {
+	if account.ChainId.Namespace != "eip155" {
+		return fmt.Errorf("account %s is not an eip155 account: %w", account, errors.ErrUnsupported)
+	}
+
+	if len(signature) != 65 {
+		return fmt.Errorf("eip155 signature must be 65 bytes, got %d: %w", len(signature), errors.ErrUnauthorised)
+	}
+
+	// Wallets use either 0 and 1 or 27 and 28 for the recovery id
+	recoveryId := signature[64]
+	if recoveryId >= 27 {
+		recoveryId -= 27
+	}
+	if recoveryId > 1 {
+		return fmt.Errorf("invalid eip155 signature recovery id: %w", errors.ErrUnauthorised)
+	}
+
+	compact := append([]byte{27 + recoveryId}, signature[:64]...)
+	key, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return fmt.Errorf("invalid eip155 signature: %v: %w", err, errors.ErrUnauthorised)
+	}
+
+	return verifySigner(account, key)
+}
+
+// verifySigner checks that the account is the address of a recovered key
+func verifySigner(account blockchain.AccountId, key *secp256k1.PublicKey) error {
+	signer, err := blockchain.NewAccountIdFromPublicKey(account.ChainId, key.SerializeUncompressed())
+	if err != nil {
+		return err
+	}
+
+	if !signer.Equal(account) {
+		return fmt.Errorf("signature is by %s, not %s: %w", signer.Address, account.Address, errors.ErrUnauthorised)
+	}
+
+	return nil
+}
+
+// VerifySolanaSignature verifies an ed25519 signature over a message by a
+// solana account, whose address is its public key
+func VerifySolanaSignature(account blockchain.AccountId, message, signature []byte) error {
+	if account.ChainId.Namespace != "solana" {
+		return fmt.Errorf("account %s is not a solana account: %w", account, errors.ErrUnsupported)
+	}
+
+	publicKey, err := base58.Decode(account.Address)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid solana address %s: %w", account.Address, errors.ErrInvalid)
+	}
+
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(publicKey, message, signature) {
+		return fmt.Errorf("invalid solana signature for %s: %w", account.Address, errors.ErrUnauthorised)
+	}
+
+	return nil
+}
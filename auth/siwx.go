@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+)
+
+// SIWxMessage is a CAIP-122 Sign-In-With-X message, the chain agnostic form of
+// an EIP-4361 Sign-In-With-Ethereum message
+type SIWxMessage struct {
+	// Domain is the authority requesting the sign-in, e.g. example.com or
+	// https://example.com:8080
+	Domain    types.URL
+	AccountId blockchain.AccountId
+	// Statement is an optional human readable assertion, without newlines
+	Statement string
+	// URI is the resource that is the subject of the sign-in
+	URI     types.URL
+	Version string
+	// Nonce is a random alphanumeric string of at least 8 characters chosen by
+	// the server to prevent replay
+	Nonce          string
+	IssuedAt       types.Time
+	ExpirationTime *types.Time
+	NotBefore      *types.Time
+	RequestId      string
+	Resources      []types.URL
+}
+
+// SIWxVersion is the current version of the message format
+const SIWxVersion = "1"
+
+// siwxChainNames maps namespaces to the chain name used in the message header
+var siwxChainNames = map[string]string{
+	"eip155": "Ethereum",
+	"solana": "Solana",
+	"bip122": "Bitcoin",
+}
+
+var (
+	siwxHeaderRegex = regexp.MustCompile(`^(?:([a-zA-Z][a-zA-Z0-9+.-]*)://)?([^\s/?#]+) wants you to sign in with your (\S+) account:$`)
+	siwxNonceRegex  = regexp.MustCompile(`^[a-zA-Z0-9]{8,}$`)
+)
+
+// NewSIWxNonce returns a random nonce for a sign-in message
+func NewSIWxNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Format returns the message to be signed, checking that it can be parsed by
+// Parse. Messages for accounts in namespaces without a sign-in chain name
+// return an error wrapping errors.ErrUnsupported.
+func (m SIWxMessage) Format() (string, error) {
+	if _, ok := siwxChainNames[m.AccountId.ChainId.Namespace]; !ok {
+		return "", fmt.Errorf("siwx is not supported in namespace %s: %w", m.AccountId.ChainId.Namespace, errors.ErrUnsupported)
+	}
+
+	if strings.Contains(m.Statement, "\n") {
+		return "", fmt.Errorf("siwx statement must not contain newlines: %w", errors.ErrInvalid)
+	}
+
+	if !siwxNonceRegex.MatchString(m.Nonce) {
+		return "", fmt.Errorf("siwx nonce must be at least 8 alphanumeric characters: %w", errors.ErrInvalid)
+	}
+
+	return m.String(), nil
+}
+
+// String returns the message to be signed without checking it, use Format to
+// build messages that must be parsed again
+func (m SIWxMessage) String() string {
+	address := m.AccountId.Address
+	if checksummed, err := m.AccountId.ChecksumAddress(); err == nil {
+		address = checksummed
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s wants you to sign in with your %s account:\n", siwxAuthority(m.Domain), siwxChainNames[m.AccountId.ChainId.Namespace])
+	sb.WriteString(address + "\n\n")
+	if m.Statement != "" {
+		sb.WriteString(m.Statement + "\n")
+	}
+	sb.WriteString("\n")
+
+	version := m.Version
+	if version == "" {
+		version = SIWxVersion
+	}
+
+	fmt.Fprintf(&sb, "URI: %s\n", m.URI.String())
+	fmt.Fprintf(&sb, "Version: %s\n", version)
+	fmt.Fprintf(&sb, "Chain ID: %s\n", m.AccountId.ChainId.Reference)
+	fmt.Fprintf(&sb, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&sb, "Issued At: %s", formatSIWxTime(m.IssuedAt))
+	if m.ExpirationTime != nil {
+		fmt.Fprintf(&sb, "\nExpiration Time: %s", formatSIWxTime(*m.ExpirationTime))
+	}
+	if m.NotBefore != nil {
+		fmt.Fprintf(&sb, "\nNot Before: %s", formatSIWxTime(*m.NotBefore))
+	}
+	if m.RequestId != "" {
+		fmt.Fprintf(&sb, "\nRequest ID: %s", m.RequestId)
+	}
+	if len(m.Resources) > 0 {
+		sb.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			fmt.Fprintf(&sb, "\n- %s", resource.String())
+		}
+	}
+
+	return sb.String()
+}
+
+// siwxAuthority returns the domain of a message, with its scheme if set
+func siwxAuthority(u types.URL) string {
+	if u.Scheme != "" {
+		return u.Scheme + "://" + siwxHost(u)
+	}
+	return siwxHost(u)
+}
+
+// siwxHost returns the host and port of a domain
+func siwxHost(u types.URL) string {
+	if u.Host == "" && u.Scheme == "" {
+		// example.com parses as a relative path
+		return u.Path
+	}
+	return u.Host
+}
+
+func formatSIWxTime(t types.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Parse parses a string into a sign-in message
+func (m *SIWxMessage) Parse(s string) error {
+	lines := strings.Split(s, "\n")
+	if len(lines) < 9 {
+		return fmt.Errorf("siwx message is too short: %w", errors.ErrInvalid)
+	}
+
+	header := siwxHeaderRegex.FindStringSubmatch(lines[0])
+	if header == nil {
+		return fmt.Errorf("invalid siwx message header %q: %w", lines[0], errors.ErrInvalid)
+	}
+
+	namespace := ""
+	for ns, name := range siwxChainNames {
+		if name == header[3] {
+			namespace = ns
+		}
+	}
+	if namespace == "" {
+		return fmt.Errorf("unknown siwx chain %s: %w", header[3], errors.ErrUnsupported)
+	}
+
+	msg := SIWxMessage{Domain: types.URL{URL: url.URL{Scheme: header[1], Host: header[2]}}}
+	address := lines[1]
+
+	if lines[2] != "" {
+		return fmt.Errorf("siwx address must be followed by a blank line: %w", errors.ErrInvalid)
+	}
+
+	// The statement is optional, but the blank line following it is not
+	i := 3
+	if lines[i] != "" {
+		msg.Statement = lines[i]
+		i++
+		if lines[i] != "" {
+			return fmt.Errorf("siwx statement must be followed by a blank line: %w", errors.ErrInvalid)
+		}
+	}
+	i++
+
+	// Fields follow in a fixed order, with the optional fields last
+	field := func(name string) (string, bool) {
+		prefix := name + ": "
+		if i < len(lines) && strings.HasPrefix(lines[i], prefix) {
+			i++
+			return strings.TrimPrefix(lines[i-1], prefix), true
+		}
+		return "", false
+	}
+
+	required := make([]string, 0, 5)
+	for _, name := range []string{"URI", "Version", "Chain ID", "Nonce", "Issued At"} {
+		value, ok := field(name)
+		if !ok {
+			return fmt.Errorf("siwx message is missing %s: %w", name, errors.ErrInvalid)
+		}
+		required = append(required, value)
+	}
+	uri, version, reference, nonce, issuedAt := required[0], required[1], required[2], required[3], required[4]
+
+	var err error
+	if msg.URI, err = types.Parse(uri); err != nil || !msg.URI.IsAbs() {
+		return fmt.Errorf("invalid siwx uri %q: %w", uri, errors.ErrInvalid)
+	}
+
+	if msg.Version = version; version != SIWxVersion {
+		return fmt.Errorf("unsupported siwx version %s: %w", version, errors.ErrUnsupported)
+	}
+
+	chainId, err := blockchain.NewChainId(namespace, reference)
+	if err != nil {
+		return fmt.Errorf("invalid siwx chain id: %v: %w", err, errors.ErrInvalid)
+	}
+	if msg.AccountId, err = blockchain.NewAccountId(chainId, address); err != nil {
+		return fmt.Errorf("invalid siwx address: %v: %w", err, errors.ErrInvalid)
+	}
+
+	// EIP-4361 requires eip155 addresses in their EIP-55 checksum form
+	if checksummed, err := msg.AccountId.ChecksumAddress(); err == nil && address != checksummed {
+		return fmt.Errorf("siwx address %s is not checksummed: %w", address, errors.ErrInvalid)
+	}
+
+	if msg.Nonce = nonce; !siwxNonceRegex.MatchString(nonce) {
+		return fmt.Errorf("siwx nonce must be at least 8 alphanumeric characters: %w", errors.ErrInvalid)
+	}
+
+	if msg.IssuedAt, err = parseSIWxTime(issuedAt); err != nil {
+		return err
+	}
+
+	if expirationTime, ok := field("Expiration Time"); ok {
+		t, err := parseSIWxTime(expirationTime)
+		if err != nil {
+			return err
+		}
+		msg.ExpirationTime = &t
+	}
+
+	if notBefore, ok := field("Not Before"); ok {
+		t, err := parseSIWxTime(notBefore)
+		if err != nil {
+			return err
+		}
+		msg.NotBefore = &t
+	}
+
+	msg.RequestId, _ = field("Request ID")
+
+	if i < len(lines) && lines[i] == "Resources:" {
+		for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+			resource, err := types.Parse(strings.TrimPrefix(lines[i], "- "))
+			if err != nil || !resource.IsAbs() {
+				return fmt.Errorf("invalid siwx resource %q: %w", lines[i], errors.ErrInvalid)
+			}
+			msg.Resources = append(msg.Resources, resource)
+		}
+	}
+
+	if i != len(lines) {
+		return fmt.Errorf("unexpected siwx message line %q: %w", lines[i], errors.ErrInvalid)
+	}
+
+	*m = msg
+	return nil
+}
+
+func parseSIWxTime(s string) (types.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return types.Time{}, fmt.Errorf("invalid siwx time %q: %w", s, errors.ErrInvalid)
+	}
+
+	return types.Time{Time: t}, nil
+}
+
+// ParseSIWxMessage parses a string into a sign-in message
+func ParseSIWxMessage(s string) (SIWxMessage, error) {
+	var m SIWxMessage
+	err := m.Parse(s)
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// Validate checks that the message was issued by a domain with a nonce and is
+// valid at a time, returning an error wrapping errors.ErrUnauthorised if not.
+// The domain is compared by host and port, and by scheme if both have one.
+func (m SIWxMessage) Validate(domain types.URL, nonce string, now time.Time) error {
+	if !strings.EqualFold(siwxHost(domain), siwxHost(m.Domain)) ||
+		(domain.Scheme != "" && m.Domain.Scheme != "" && !strings.EqualFold(domain.Scheme, m.Domain.Scheme)) {
+		return fmt.Errorf("siwx message is for domain %s, not %s: %w", siwxAuthority(m.Domain), siwxAuthority(domain), errors.ErrUnauthorised)
+	}
+
+	if m.Nonce != nonce {
+		return fmt.Errorf("siwx message nonce does not match: %w", errors.ErrUnauthorised)
+	}
+
+	if m.ExpirationTime != nil && !now.Before(m.ExpirationTime.Time) {
+		return fmt.Errorf("siwx message expired at %s: %w", formatSIWxTime(*m.ExpirationTime), errors.ErrUnauthorised)
+	}
+
+	if m.NotBefore != nil && now.Before(m.NotBefore.Time) {
+		return fmt.Errorf("siwx message is not valid before %s: %w", formatSIWxTime(*m.NotBefore), errors.ErrUnauthorised)
+	}
+
+	return nil
+}
+
+// VerifySIWxMessage parses a signed sign-in message, validates it against the
+// expected domain and nonce and verifies that its signature was produced by
+// its account. The signature is checked against the message exactly as it was
+// signed.
+func VerifySIWxMessage(message string, signature []byte, domain types.URL, nonce string, now time.Time) (SIWxMessage, error) {
+	m, err := ParseSIWxMessage(message)
+	if err != nil {
+		return SIWxMessage{}, err
+	}
+
+	if err := m.Validate(domain, nonce, now); err != nil {
+		return SIWxMessage{}, err
+	}
+
+	if err := VerifyMessageSignature(m.AccountId, []byte(message), signature); err != nil {
+		return SIWxMessage{}, err
+	}
+
+	return m, nil
+}
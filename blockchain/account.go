@@ -13,13 +13,19 @@ import (
 	"github.com/offblocks/offblocks-common/util"
 )
 
+// AccountId is a CAIP-10 account id. Account ids should be created with
+// NewAccountId or parsed so that their address is in the canonical form for the
+// chain namespace; struct literals are validated and canonicalised when
+// written with Value.
 type AccountId struct {
 	ChainId ChainId
 	Address string
 }
 
 var (
-	addressRegex = regexp.MustCompile("[a-zA-Z0-9]{1,64}")
+	addressRegex = regexp.MustCompile("^[-.%a-zA-Z0-9]{1,128}$")
+
+	legacyAddressRegex = regexp.MustCompile("[a-zA-Z0-9]{1,64}")
 )
 
 func NewAccountId(chainId ChainId, address string) (AccountId, error) {
@@ -28,7 +34,7 @@ func NewAccountId(chainId ChainId, address string) (AccountId, error) {
 		return AccountId{}, err
 	}
 
-	return aID, nil
+	return aID.normalise()
 }
 
 func (a AccountId) validate() error {
@@ -37,7 +43,41 @@ func (a AccountId) validate() error {
 	}
 
 	if ok := addressRegex.Match([]byte(a.Address)); !ok {
-		return errors.New("namespace does not match spec")
+		return errors.New("address does not match spec")
+	}
+
+	if !validPercentEncoding(a.Address) {
+		return errors.New("address contains invalid percent-encoding")
+	}
+
+	return nil
+}
+
+// normalise applies the address normaliser registered for the chain namespace,
+// returning the account id with its address in canonical form
+func (a AccountId) normalise() (AccountId, error) {
+	normaliser, ok := accountNamespaceNormaliser(a.ChainId.Namespace)
+	if !ok {
+		return a, nil
+	}
+
+	address, err := normaliser(a.ChainId, a.Address)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	return AccountId{a.ChainId, address}, nil
+}
+
+// validateLegacy validates the account id against the unanchored patterns used
+// before strict CAIP-10 validation was introduced
+func (a AccountId) validateLegacy() error {
+	if err := a.ChainId.validateLegacy(); err != nil {
+		return err
+	}
+
+	if ok := legacyAddressRegex.Match([]byte(a.Address)); !ok {
+		return errors.New("address does not match spec")
 	}
 
 	return nil
@@ -50,19 +90,56 @@ func (a AccountId) String() string {
 
 // Parse parses a string into a account id from the string form, chain_namespace:chain_reference:address
 func (a *AccountId) Parse(s string) error {
+	return a.parse(s, true)
+}
+
+func (a *AccountId) parse(s string, strict bool) error {
 	split := strings.SplitN(s, ":", 3)
 	if len(split) != 3 {
 		return fmt.Errorf("invalid account id: %s", s)
 	}
 
-	*a = AccountId{ChainId{split[0], split[1]}, split[2]}
-	if err := a.validate(); err != nil {
+	aID, err := AccountId{ChainId{split[0], split[1]}, split[2]}.canonical(strict)
+	if err != nil {
 		return err
 	}
 
+	*a = aID
 	return nil
 }
 
+// canonical validates the account id, against the legacy patterns unless
+// strict, and returns it with its address in canonical form
+func (a AccountId) canonical(strict bool) (AccountId, error) {
+	validate := a.validate
+	if !strict {
+		validate = a.validateLegacy
+	}
+
+	if err := validate(); err != nil {
+		return AccountId{}, err
+	}
+
+	return a.normalise()
+}
+
+// Canonical returns the account id with its address in the canonical form for
+// the chain namespace, or the account id unchanged if it cannot be normalised
+func (a AccountId) Canonical() AccountId {
+	canonical, err := a.normalise()
+	if err != nil {
+		return a
+	}
+
+	return canonical
+}
+
+// Equal reports whether two account ids refer to the same account, treating
+// different spellings of the same address as equal
+func (a AccountId) Equal(other AccountId) bool {
+	return a.Canonical() == other.Canonical()
+}
+
 // MustParse parses a string into a account id from the string form, chain_namespace:chain_reference:address
 // and panics if there is an error
 func (c *AccountId) MustParse(s string) {
@@ -147,7 +224,12 @@ func (a AccountId) MarshalProto() (string, error) {
 }
 
 func (a AccountId) Value() (driver.Value, error) {
-	return a.String(), nil
+	canonical, err := a.canonical(!LenientScan())
+	if err != nil {
+		return nil, fmt.Errorf("invalid account id %s: %w", a, err)
+	}
+
+	return canonical.String(), nil
 }
 
 func (a *AccountId) Scan(src interface{}) error {
@@ -160,7 +242,7 @@ func (a *AccountId) Scan(src interface{}) error {
 		return nil
 	}
 
-	if err := a.Parse(i.String); err != nil {
+	if err := a.parse(i.String, !LenientScan()); err != nil {
 		return err
 	}
 
@@ -173,7 +255,7 @@ func (a AccountId) MarshalGQL(w io.Writer) {
 
 func (a *AccountId) UnmarshalGQL(v interface{}) error {
 	if id, ok := v.(string); ok {
-		if err := a.Parse(id); err != nil {
+		if err := unmarshalGQLId(id, a.Parse); err != nil {
 			return fmt.Errorf("unmarshalling account id: %w", err)
 		}
 	}
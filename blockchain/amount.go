@@ -0,0 +1,214 @@
+package blockchain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+)
+
+// ErrAssetMismatch is returned when combining amounts of different assets
+var ErrAssetMismatch = errors.New("amounts are of different assets")
+
+// Amount is a quantity of an asset in display units, e.g. 1.5 ETH rather than
+// 1500000000000000000 wei
+type Amount struct {
+	Asset    AssetId
+	Quantity types.Decimal
+}
+
+func NewAmount(asset AssetId, quantity types.Decimal) Amount {
+	return Amount{asset, quantity}
+}
+
+// NewAmountFromBaseUnits creates an amount from an integer number of base units,
+// such as wei, satoshi or lamports, of an asset with the given decimals. Nil
+// units are treated as zero.
+func NewAmountFromBaseUnits(asset AssetId, units *big.Int, decimals int32) Amount {
+	if units == nil {
+		units = new(big.Int)
+	}
+
+	return Amount{asset, types.Decimal{Decimal: decimal.NewFromBigInt(units, -decimals)}}
+}
+
+// BaseUnits returns the amount as an integer number of base units of an asset
+// with the given decimals. It fails rather than rounds if the amount is more
+// precise than a single base unit.
+func (a Amount) BaseUnits(decimals int32) (*big.Int, error) {
+	shifted := a.Quantity.Shift(decimals)
+	if !shifted.IsInteger() {
+		return nil, fmt.Errorf("amount %s is more precise than %d decimals", a.Quantity, decimals)
+	}
+
+	return shifted.BigInt(), nil
+}
+
+// Add returns the sum of two amounts of the same asset
+func (a Amount) Add(b Amount) (Amount, error) {
+	if err := a.checkAsset(b); err != nil {
+		return Amount{}, err
+	}
+
+	return Amount{a.Asset, types.Decimal{Decimal: a.Quantity.Add(b.Quantity.Decimal)}}, nil
+}
+
+// Sub returns the difference of two amounts of the same asset
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if err := a.checkAsset(b); err != nil {
+		return Amount{}, err
+	}
+
+	return Amount{a.Asset, types.Decimal{Decimal: a.Quantity.Sub(b.Quantity.Decimal)}}, nil
+}
+
+// Cmp compares two amounts of the same asset, returning -1, 0 or +1
+func (a Amount) Cmp(b Amount) (int, error) {
+	if err := a.checkAsset(b); err != nil {
+		return 0, err
+	}
+
+	return a.Quantity.Cmp(b.Quantity.Decimal), nil
+}
+
+// Neg returns the amount with its sign inverted
+func (a Amount) Neg() Amount {
+	return Amount{a.Asset, types.Decimal{Decimal: a.Quantity.Neg()}}
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.Quantity.IsZero()
+}
+
+// IsNegative reports whether the amount is less than zero
+func (a Amount) IsNegative() bool {
+	return a.Quantity.Sign() < 0
+}
+
+func (a Amount) checkAsset(b Amount) error {
+	if a.Asset != b.Asset {
+		return fmt.Errorf("%w: %s and %s", ErrAssetMismatch, a.Asset, b.Asset)
+	}
+
+	return nil
+}
+
+// String returns the string form of amount, quantity asset_id
+func (a Amount) String() string {
+	return a.Quantity.String() + " " + a.Asset.String()
+}
+
+// Parse parses a string into an amount from the string form, quantity asset_id
+func (a *Amount) Parse(s string) error {
+	quantity, asset, ok := strings.Cut(s, " ")
+	if !ok {
+		return fmt.Errorf("invalid amount: %s", s)
+	}
+
+	d, err := decimal.NewFromString(quantity)
+	if err != nil {
+		return fmt.Errorf("invalid amount quantity %s: %w", s, err)
+	}
+
+	assetId, err := ParseAssetId(asset)
+	if err != nil {
+		return err
+	}
+
+	*a = Amount{assetId, types.Decimal{Decimal: d}}
+	return nil
+}
+
+// ParseAmount parses a string into an amount from the string form, quantity asset_id
+func ParseAmount(s string) (Amount, error) {
+	var a Amount
+	err := a.Parse(s)
+	if err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for XML
+// deserialization
+func (a *Amount) UnmarshalText(data []byte) error {
+	amount, err := ParseAmount(string(data))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for XML
+// serialization
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+type amountJSON struct {
+	Asset    AssetId         `json:"asset"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var amount amountJSON
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return fmt.Errorf("error decoding amount '%s': %s", data, err)
+	}
+
+	*a = Amount{amount.Asset, types.Decimal{Decimal: amount.Quantity}}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(amountJSON{a.Asset, a.Quantity.Decimal})
+}
+
+func (a *Amount) UnmarshalProto(pb string) error {
+	amount, err := ParseAmount(pb)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+func (a Amount) MarshalProto() (string, error) {
+	return a.String(), nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	var i sql.NullString
+	if err := i.Scan(src); err != nil {
+		return fmt.Errorf("scanning amount: %w", err)
+	}
+
+	if !i.Valid {
+		return nil
+	}
+
+	if err := a.Parse(i.String); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -13,27 +13,57 @@ import (
 	"github.com/offblocks/offblocks-common/util"
 )
 
+// AssetId is a CAIP-19 asset id. Asset ids should be created with NewAssetId or
+// parsed so that their reference is in the canonical form for the asset
+// namespace; struct literals are validated and canonicalised when written with
+// Value.
 type AssetId struct {
 	ChainId   ChainId
 	Namespace string
 	Reference string
+	// TokenId identifies an individual token within a non-fungible or
+	// semi-fungible asset and is empty for fungible assets
+	TokenId string
 }
 
 var (
-	assetNamespaceRegex = regexp.MustCompile("[-a-z0-9]{3,8}")
-	assetReferenceRegex = regexp.MustCompile("[-a-zA-Z0-9]{1,64}")
+	assetNamespaceRegex = regexp.MustCompile("^[-a-z0-9]{3,8}$")
+	assetReferenceRegex = regexp.MustCompile("^[-.%a-zA-Z0-9]{1,128}$")
+	assetTokenIdRegex   = regexp.MustCompile("^[-.%a-zA-Z0-9]{1,78}$")
+
+	legacyAssetNamespaceRegex = regexp.MustCompile("[-a-z0-9]{3,8}")
+	legacyAssetReferenceRegex = regexp.MustCompile("[-a-zA-Z0-9]{1,64}")
 )
 
 func NewAssetId(chainID ChainId, namespace, reference string) (AssetId, error) {
-	aID := AssetId{chainID, namespace, reference}
+	aID := AssetId{chainID, namespace, reference, ""}
+	if err := aID.validate(); err != nil {
+		return AssetId{}, err
+	}
+
+	return aID.normalise()
+}
+
+// NewTokenAssetId creates an asset id for an individual token within a
+// non-fungible or semi-fungible asset
+func NewTokenAssetId(chainID ChainId, namespace, reference, tokenId string) (AssetId, error) {
+	if tokenId == "" {
+		return AssetId{}, errors.New("asset token id is empty")
+	}
+
+	aID := AssetId{chainID, namespace, reference, tokenId}
 	if err := aID.validate(); err != nil {
 		return AssetId{}, err
 	}
 
-	return aID, nil
+	return aID.normalise()
 }
 
 func (a AssetId) validate() error {
+	if err := a.ChainId.validate(); err != nil {
+		return err
+	}
+
 	if ok := assetNamespaceRegex.Match([]byte(a.Namespace)); !ok {
 		return errors.New("asset namespace does not match spec")
 	}
@@ -42,23 +72,95 @@ func (a AssetId) validate() error {
 		return errors.New("asset reference does not match spec")
 	}
 
+	if !validPercentEncoding(a.Reference) {
+		return errors.New("asset reference contains invalid percent-encoding")
+	}
+
+	if a.TokenId != "" {
+		if ok := assetTokenIdRegex.Match([]byte(a.TokenId)); !ok {
+			return errors.New("asset token id does not match spec")
+		}
+
+		if !validPercentEncoding(a.TokenId) {
+			return errors.New("asset token id contains invalid percent-encoding")
+		}
+	}
+
+	return nil
+}
+
+// normalise applies the asset namespace registered for the asset id, returning
+// the asset id with its reference in canonical form
+func (a AssetId) normalise() (AssetId, error) {
+	assetNamespace, ok := lookupAssetNamespace(a.Namespace)
+	if !ok {
+		return a, nil
+	}
+
+	return assetNamespace.normalise(a)
+}
+
+// validateLegacy validates the asset id against the unanchored patterns used
+// before strict CAIP-19 validation was introduced
+func (a AssetId) validateLegacy() error {
+	if err := a.ChainId.validateLegacy(); err != nil {
+		return err
+	}
+
+	if ok := legacyAssetNamespaceRegex.Match([]byte(a.Namespace)); !ok {
+		return errors.New("asset namespace does not match spec")
+	}
+
+	if ok := legacyAssetReferenceRegex.Match([]byte(a.Reference)); !ok {
+		return errors.New("asset reference does not match spec")
+	}
+
 	return nil
 }
 
 // String returns the string form of asset id, chain_namespace:chain_reference/namespace:reference
+// followed by /token_id for individual tokens
 func (a AssetId) String() string {
-	return a.ChainId.String() + "/" + a.Namespace + ":" + a.Reference
+	s := a.ChainId.String() + "/" + a.Namespace + ":" + a.Reference
+	if a.TokenId != "" {
+		s += "/" + a.TokenId
+	}
+
+	return s
+}
+
+// IsFungible reports whether the asset id refers to a fungible asset. Assets in
+// non-fungible namespaces such as erc721 and erc1155 are not fungible whether or
+// not they have a token id, and assets in namespaces that are not registered are
+// fungible unless they have a token id.
+func (a AssetId) IsFungible() bool {
+	if assetNamespace, ok := lookupAssetNamespace(a.Namespace); ok {
+		return !assetNamespace.NonFungible
+	}
+
+	return a.TokenId == ""
+}
+
+// Collection returns the asset id of the collection an individual token belongs
+// to, or the asset id unchanged if it has no token id
+func (a AssetId) Collection() AssetId {
+	return AssetId{a.ChainId, a.Namespace, a.Reference, ""}
 }
 
 // Parse parses a string into a asset id from the string form, chain_namespace:chain_reference/namespace:reference
+// with an optional /token_id suffix
 func (a *AssetId) Parse(s string) error {
+	return a.parse(s, true)
+}
+
+func (a *AssetId) parse(s string, strict bool) error {
 	components := strings.SplitN(s, "/", 2)
 	if len(components) != 2 {
 		return fmt.Errorf("invalid asset id: %s", s)
 	}
 
 	cID := new(ChainId)
-	if err := cID.Parse(components[0]); err != nil {
+	if err := cID.parse(components[0], strict); err != nil {
 		return err
 	}
 
@@ -67,14 +169,46 @@ func (a *AssetId) Parse(s string) error {
 		return fmt.Errorf("invalid asset id: %s", s)
 	}
 
-	*a = AssetId{*cID, asset[0], asset[1]}
-	if err := a.validate(); err != nil {
+	reference, tokenId, hasTokenId := strings.Cut(asset[1], "/")
+	if hasTokenId && tokenId == "" {
+		return fmt.Errorf("invalid asset id: %s", s)
+	}
+
+	aID, err := AssetId{*cID, asset[0], reference, tokenId}.canonical(strict)
+	if err != nil {
 		return err
 	}
 
+	*a = aID
 	return nil
 }
 
+// canonical validates the asset id, against the legacy patterns unless strict,
+// and returns it with its reference in canonical form
+func (a AssetId) canonical(strict bool) (AssetId, error) {
+	validate := a.validate
+	if !strict {
+		validate = a.validateLegacy
+	}
+
+	if err := validate(); err != nil {
+		return AssetId{}, err
+	}
+
+	return a.normalise()
+}
+
+// Canonical returns the asset id with its reference in the canonical form for
+// the asset namespace, or the asset id unchanged if it cannot be normalised
+func (a AssetId) Canonical() AssetId {
+	canonical, err := a.normalise()
+	if err != nil {
+		return a
+	}
+
+	return canonical
+}
+
 // MustParse parses a string into a asset id from the string form, chain_namespace:chain_reference/namespace:reference
 // and panics if there is an error
 func (a *AssetId) MustParse(s string) {
@@ -159,20 +293,25 @@ func (a AssetId) MarshalProto() (string, error) {
 }
 
 func (a AssetId) Value() (driver.Value, error) {
-	return a.String(), nil
+	canonical, err := a.canonical(!LenientScan())
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset id %s: %w", a, err)
+	}
+
+	return canonical.String(), nil
 }
 
 func (a *AssetId) Scan(src interface{}) error {
 	var i sql.NullString
 	if err := i.Scan(src); err != nil {
-		return fmt.Errorf("scanning account id: %w", err)
+		return fmt.Errorf("scanning asset id: %w", err)
 	}
 
 	if !i.Valid {
 		return nil
 	}
 
-	if err := a.Parse(i.String); err != nil {
+	if err := a.parse(i.String, !LenientScan()); err != nil {
 		return err
 	}
 
@@ -185,7 +324,7 @@ func (a AssetId) MarshalGQL(w io.Writer) {
 
 func (a *AssetId) UnmarshalGQL(v interface{}) error {
 	if id, ok := v.(string); ok {
-		if err := a.Parse(id); err != nil {
+		if err := unmarshalGQLId(id, a.Parse); err != nil {
 			return fmt.Errorf("unmarshalling asset id: %w", err)
 		}
 	}
@@ -0,0 +1,274 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/offblocks/offblocks-common/internal/bech32"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+// BitcoinScriptType identifies the output script an address pays to
+type BitcoinScriptType string
+
+const (
+	BitcoinP2PKH          BitcoinScriptType = "p2pkh"
+	BitcoinP2SH           BitcoinScriptType = "p2sh"
+	BitcoinP2WPKH         BitcoinScriptType = "p2wpkh"
+	BitcoinP2WSH          BitcoinScriptType = "p2wsh"
+	BitcoinP2TR           BitcoinScriptType = "p2tr"
+	BitcoinWitnessUnknown BitcoinScriptType = "witness_unknown"
+)
+
+// BitcoinAddress is a decoded bip122 account address
+type BitcoinAddress struct {
+	ScriptType     BitcoinScriptType
+	WitnessVersion int
+	// Program is the public key or script hash for base58 addresses, or the
+	// witness program for segwit addresses
+	Program []byte
+}
+
+type bitcoinNetwork struct {
+	pubKeyHashPrefix   byte
+	scriptHashPrefixes []byte
+	hrp                string
+	// coinType is the SLIP-44 coin type of the network's native asset
+	coinType uint32
+	// testnet is set for test networks, whose extended keys are serialized with
+	// the testnet versions such as tpub
+	testnet bool
+}
+
+// bitcoinNetworks maps bip122 chain references, the first 32 hex characters of
+// the genesis block hash, to their address parameters
+var bitcoinNetworks = map[string]bitcoinNetwork{
+	// Bitcoin mainnet
+	"000000000019d6689c085ae165831e93": {0x00, []byte{0x05}, "bc", 0, false},
+	// Bitcoin testnet3
+	"000000000933ea01ad0ee984209779ba": {0x6f, []byte{0xc4}, "tb", 1, true},
+	// Bitcoin testnet4
+	"00000000da84f2bafbbc53dee25a72ae": {0x6f, []byte{0xc4}, "tb", 1, true},
+	// Bitcoin signet
+	"00000008819873e925422c1ff0f99f7c": {0x6f, []byte{0xc4}, "tb", 1, true},
+	// Bitcoin regtest
+	"0f9188f13cb7b2c71f2a335e3a4fc328": {0x6f, []byte{0xc4}, "bcrt", 1, true},
+	// Litecoin mainnet
+	"12a765e31ffd4059bada1e25190f6e98": {0x30, []byte{0x32, 0x05}, "ltc", 2, false},
+}
+
+// normaliseBIP122Address validates an address against the network of the
+// chain, returning segwit addresses in lowercase. Addresses on chains with
+// unknown network parameters are returned unchanged.
+func normaliseBIP122Address(chainId ChainId, address string) (string, error) {
+	network, ok := bitcoinNetworks[chainId.Reference]
+	if !ok {
+		return address, nil
+	}
+
+	if _, err := decodeBitcoinAddress(network, address); err != nil {
+		return "", err
+	}
+
+	if hasBech32Prefix(network, address) {
+		return strings.ToLower(address), nil
+	}
+
+	return address, nil
+}
+
+// BitcoinAddress decodes a bip122 account address, checking that it belongs to
+// the network of the account's chain
+func (a AccountId) BitcoinAddress() (BitcoinAddress, error) {
+	if a.ChainId.Namespace != "bip122" {
+		return BitcoinAddress{}, fmt.Errorf("bitcoin addresses are not supported in namespace %s", a.ChainId.Namespace)
+	}
+
+	network, ok := bitcoinNetworks[a.ChainId.Reference]
+	if !ok {
+		return BitcoinAddress{}, fmt.Errorf("unknown bip122 network: %s", a.ChainId.Reference)
+	}
+
+	return decodeBitcoinAddress(network, a.Address)
+}
+
+func hasBech32Prefix(network bitcoinNetwork, address string) bool {
+	return strings.HasPrefix(strings.ToLower(address), network.hrp+"1")
+}
+
+func decodeBitcoinAddress(network bitcoinNetwork, address string) (BitcoinAddress, error) {
+	if hasBech32Prefix(network, address) {
+		return decodeSegWitAddress(network, address)
+	}
+
+	payload, err := base58.CheckDecode(address)
+	if err != nil {
+		return BitcoinAddress{}, fmt.Errorf("invalid bitcoin address %s: %w", address, err)
+	}
+
+	if len(payload) != 21 {
+		return BitcoinAddress{}, fmt.Errorf("invalid bitcoin address length: %s", address)
+	}
+
+	switch {
+	case payload[0] == network.pubKeyHashPrefix:
+		return BitcoinAddress{ScriptType: BitcoinP2PKH, Program: payload[1:]}, nil
+	case bytes.IndexByte(network.scriptHashPrefixes, payload[0]) >= 0:
+		return BitcoinAddress{ScriptType: BitcoinP2SH, Program: payload[1:]}, nil
+	default:
+		return BitcoinAddress{}, fmt.Errorf("bitcoin address is for a different network: %s", address)
+	}
+}
+
+func decodeSegWitAddress(network bitcoinNetwork, address string) (BitcoinAddress, error) {
+	hrp, data, variant, err := bech32.Decode(address, bech32.MaxLength)
+	if err != nil {
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit address %s: %w", address, err)
+	}
+
+	if hrp != network.hrp {
+		return BitcoinAddress{}, fmt.Errorf("segwit address is for a different network: %s", address)
+	}
+
+	if len(data) < 1 || data[0] > 16 {
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit witness version: %s", address)
+	}
+
+	version := int(data[0])
+	program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit address %s: %w", address, err)
+	}
+
+	if len(program) < 2 || len(program) > 40 {
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit program length: %s", address)
+	}
+
+	// BIP-350 requires version 0 programs to use Bech32 and later versions Bech32m
+	if (version == 0 && variant != bech32.Bech32) || (version != 0 && variant != bech32.Bech32m) {
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit checksum variant: %s", address)
+	}
+
+	decoded := BitcoinAddress{ScriptType: BitcoinWitnessUnknown, WitnessVersion: version, Program: program}
+	switch {
+	case version == 0 && len(program) == 20:
+		decoded.ScriptType = BitcoinP2WPKH
+	case version == 0 && len(program) == 32:
+		decoded.ScriptType = BitcoinP2WSH
+	case version == 0:
+		return BitcoinAddress{}, fmt.Errorf("invalid segwit v0 program length: %s", address)
+	case version == 1 && len(program) == 32:
+		decoded.ScriptType = BitcoinP2TR
+	}
+
+	return decoded, nil
+}
+
+// NewBitcoinAccountIdFromPublicKey derives the bip122 account id paying to a
+// secp256k1 public key with a p2pkh, p2sh, p2wpkh or p2tr script. P2SH
+// addresses wrap a p2wpkh script, as specified by BIP-49, and P2TR addresses
+// commit to the key alone, as specified by BIP-86.
+func NewBitcoinAccountIdFromPublicKey(chainId ChainId, publicKey []byte, scriptType BitcoinScriptType) (AccountId, error) {
+	if chainId.Namespace != "bip122" {
+		return AccountId{}, fmt.Errorf("bitcoin addresses are not supported in namespace %s", chainId.Namespace)
+	}
+
+	address, err := deriveBitcoinAddress(chainId, publicKey, scriptType)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	return NewAccountId(chainId, address)
+}
+
+// deriveBIP122Address derives the P2WPKH address of a secp256k1 public key
+func deriveBIP122Address(chainId ChainId, publicKey []byte) (string, error) {
+	return deriveBitcoinAddress(chainId, publicKey, BitcoinP2WPKH)
+}
+
+func deriveBitcoinAddress(chainId ChainId, publicKey []byte, scriptType BitcoinScriptType) (string, error) {
+	network, ok := bitcoinNetworks[chainId.Reference]
+	if !ok {
+		return "", fmt.Errorf("unknown bip122 network: %s", chainId.Reference)
+	}
+
+	key, err := parseSecp256k1PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	switch scriptType {
+	case BitcoinP2PKH:
+		return base58.CheckEncode(append([]byte{network.pubKeyHashPrefix}, hashes.Hash160(key.SerializeCompressed())...)), nil
+	case BitcoinP2SH:
+		redeemScript := append([]byte{0x00, 0x14}, hashes.Hash160(key.SerializeCompressed())...)
+		return base58.CheckEncode(append([]byte{network.scriptHashPrefixes[0]}, hashes.Hash160(redeemScript)...)), nil
+	case BitcoinP2WPKH:
+		return encodeSegWitAddress(network, 0, hashes.Hash160(key.SerializeCompressed()))
+	case BitcoinP2TR:
+		outputKey, err := taprootOutputKey(key)
+		if err != nil {
+			return "", err
+		}
+
+		return encodeSegWitAddress(network, 1, outputKey)
+	default:
+		return "", fmt.Errorf("deriving %s addresses is not supported", scriptType)
+	}
+}
+
+func encodeSegWitAddress(network bitcoinNetwork, version byte, program []byte) (string, error) {
+	data, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	variant := bech32.Bech32
+	if version != 0 {
+		variant = bech32.Bech32m
+	}
+
+	return bech32.Encode(network.hrp, append([]byte{version}, data...), variant)
+}
+
+// taprootOutputKey returns the x-only output key of a BIP-86 key path only
+// taproot output, the internal key tweaked by the tagged hash of itself
+func taprootOutputKey(key *secp256k1.PublicKey) ([]byte, error) {
+	// BIP-340 keys are x-only, so the internal key is lifted to its even y form
+	xOnly := key.SerializeCompressed()[1:]
+	internal, err := secp256k1.ParsePubKey(append([]byte{secp256k1.PubKeyFormatCompressedEven}, xOnly...))
+	if err != nil {
+		return nil, err
+	}
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(hashes.TaggedHash("TapTweak", xOnly)); overflow {
+		return nil, fmt.Errorf("taproot tweak exceeds the curve order")
+	}
+
+	var p, t, q secp256k1.JacobianPoint
+	internal.AsJacobian(&p)
+	secp256k1.ScalarBaseMultNonConst(&tweak, &t)
+	secp256k1.AddNonConst(&p, &t, &q)
+	q.ToAffine()
+
+	if q.X.IsZero() && q.Y.IsZero() {
+		return nil, fmt.Errorf("taproot output key is the point at infinity")
+	}
+
+	x := q.X.Bytes()
+	return x[:], nil
+}
+
+// normaliseBIP122Hash validates a 32 byte hex transaction id and returns the
+// lowercase form
+func normaliseBIP122Hash(_ ChainId, hash string) (string, error) {
+	if !hex32Regex.MatchString(hash) {
+		return "", fmt.Errorf("bip122 transaction id must be 32 byte hex: %s", hash)
+	}
+
+	return strings.ToLower(hash), nil
+}
@@ -0,0 +1,272 @@
+package blockchain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/util"
+)
+
+// BlockId identifies a block by its number, its hash or both on a chain. Block
+// ids should be created with NewBlockId, NewBlockIdFromHash or parsed so that
+// their hash is in the canonical form for the chain namespace.
+type BlockId struct {
+	ChainId ChainId
+	// Number is the height of the block, valid only if HasNumber is set
+	Number uint64
+	// HasNumber is false if only the hash of the block is known
+	HasNumber bool
+	Hash      string
+}
+
+// blockHashNormalisers holds the block hash normalisers of chain namespaces
+// whose block hashes differ in form from their transaction hashes. Block hashes
+// in other namespaces are normalised as transaction hashes.
+var blockHashNormalisers = map[string]HashNormaliser{
+	"solana": normaliseSolanaBlockhash,
+}
+
+func NewBlockId(chainId ChainId, number uint64, hash string) (BlockId, error) {
+	bID := BlockId{chainId, number, true, hash}
+	if err := bID.validate(); err != nil {
+		return BlockId{}, err
+	}
+
+	return bID.normalise()
+}
+
+// NewBlockIdFromHash creates a block id from the hash of a block whose number is
+// not known
+func NewBlockIdFromHash(chainId ChainId, hash string) (BlockId, error) {
+	bID := BlockId{chainId, 0, false, hash}
+	if err := bID.validate(); err != nil {
+		return BlockId{}, err
+	}
+
+	return bID.normalise()
+}
+
+func (b BlockId) validate() error {
+	if err := b.ChainId.validate(); err != nil {
+		return err
+	}
+
+	if !b.HasNumber && b.Hash == "" {
+		return errors.New("block id must have a number or a hash")
+	}
+
+	if b.Hash != "" {
+		if ok := hashRegex.Match([]byte(b.Hash)); !ok {
+			return errors.New("hash does not match spec")
+		}
+	}
+
+	return nil
+}
+
+// normalise applies the hash normaliser of the chain namespace, returning the
+// block id with its hash in canonical form
+func (b BlockId) normalise() (BlockId, error) {
+	if b.Hash == "" {
+		return b, nil
+	}
+
+	normaliser, ok := blockHashNormalisers[b.ChainId.Namespace]
+	if !ok {
+		if normaliser, ok = transactionNamespaceNormaliser(b.ChainId.Namespace); !ok {
+			return b, nil
+		}
+	}
+
+	hash, err := normaliser(b.ChainId, b.Hash)
+	if err != nil {
+		return BlockId{}, err
+	}
+
+	return BlockId{b.ChainId, b.Number, b.HasNumber, hash}, nil
+}
+
+// String returns the string form of block id, chain_namespace:chain_reference:number
+// followed by :hash when the hash is known. The number is empty when only the
+// hash is known.
+func (b BlockId) String() string {
+	s := b.ChainId.String() + ":"
+	if b.HasNumber {
+		s += strconv.FormatUint(b.Number, 10)
+	}
+	if b.Hash != "" {
+		s += ":" + b.Hash
+	}
+
+	return s
+}
+
+// Equal reports whether two block ids have the same chain, number and hash
+func (b BlockId) Equal(other BlockId) bool {
+	if b.ChainId != other.ChainId || b.Hash != other.Hash || b.HasNumber != other.HasNumber {
+		return false
+	}
+
+	return !b.HasNumber || b.Number == other.Number
+}
+
+// Parse parses a string into a block id from the string form, chain_namespace:chain_reference:number
+// with an optional :hash suffix, or chain_namespace:chain_reference::hash
+func (b *BlockId) Parse(s string) error {
+	split := strings.SplitN(s, ":", 4)
+	if len(split) < 3 {
+		return fmt.Errorf("invalid block id: %s", s)
+	}
+
+	bID := BlockId{ChainId: ChainId{split[0], split[1]}}
+	if split[2] != "" || len(split) == 3 {
+		number, err := strconv.ParseUint(split[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block number: %s", s)
+		}
+		bID.Number, bID.HasNumber = number, true
+	}
+
+	if len(split) == 4 {
+		if split[3] == "" {
+			return fmt.Errorf("invalid block id: %s", s)
+		}
+		bID.Hash = split[3]
+	}
+
+	if err := bID.validate(); err != nil {
+		return err
+	}
+
+	bID, err := bID.normalise()
+	if err != nil {
+		return err
+	}
+
+	*b = bID
+	return nil
+}
+
+// MustParse parses a string into a block id from the string form, chain_namespace:chain_reference:number
+// with an optional :hash suffix and panics if there is an error
+func (b *BlockId) MustParse(s string) {
+	if err := b.Parse(s); err != nil {
+		panic(err)
+	}
+}
+
+// ParseBlockId parses a string into a block id from the string form, chain_namespace:chain_reference:number
+// with an optional :hash suffix
+func ParseBlockId(s string) (BlockId, error) {
+	var b BlockId
+	err := b.Parse(s)
+	if err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+// MustParseBlockId parses a string into a block id from the string form, chain_namespace:chain_reference:number
+// with an optional :hash suffix and panics if there is an error
+func MustParseBlockId(s string) BlockId {
+	var b BlockId
+	b.MustParse(s)
+	return b
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for XML
+// deserialization
+func (b *BlockId) UnmarshalText(data []byte) error {
+	blockId, err := ParseBlockId(string(data))
+	if err != nil {
+		return err
+	}
+	*b = blockId
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for XML
+// serialization
+func (b BlockId) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *BlockId) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	str, err := util.UnquoteIfQuoted(data)
+	if err != nil {
+		return fmt.Errorf("error decoding string '%s': %s", data, err)
+	}
+
+	blockId, err := ParseBlockId(str)
+	if err != nil {
+		return err
+	}
+	*b = blockId
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b BlockId) MarshalJSON() ([]byte, error) {
+	str := "\"" + b.String() + "\""
+
+	return []byte(str), nil
+}
+
+func (b *BlockId) UnmarshalProto(pb string) error {
+	blockId, err := ParseBlockId(pb)
+	if err != nil {
+		return err
+	}
+	*b = blockId
+	return nil
+}
+
+func (b BlockId) MarshalProto() (string, error) {
+	return b.String(), nil
+}
+
+func (b BlockId) Value() (driver.Value, error) {
+	return b.String(), nil
+}
+
+func (b *BlockId) Scan(src interface{}) error {
+	var i sql.NullString
+	if err := i.Scan(src); err != nil {
+		return fmt.Errorf("scanning block id: %w", err)
+	}
+
+	if !i.Valid {
+		return nil
+	}
+
+	if err := b.Parse(i.String); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b BlockId) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(strings.ToUpper(b.String())))
+}
+
+func (b *BlockId) UnmarshalGQL(v interface{}) error {
+	if id, ok := v.(string); ok {
+		if err := unmarshalGQLId(id, b.Parse); err != nil {
+			return fmt.Errorf("unmarshalling block id: %w", err)
+		}
+	}
+
+	return nil
+}
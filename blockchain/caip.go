@@ -0,0 +1,50 @@
+package blockchain
+
+import (
+	"regexp"
+	"sync/atomic"
+)
+
+var (
+	// hex32Regex matches 32 bytes of hex in either case
+	hex32Regex = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+)
+
+// lenientScan is set by SetLenientScan
+var lenientScan atomic.Bool
+
+// SetLenientScan relaxes the validation applied when identifiers are read from
+// a database with Scan to the unanchored patterns used before strict CAIP
+// validation, so that previously stored rows which fail strict parsing can
+// still be read. Validators and normalisers registered for a namespace still
+// apply. It is safe to call concurrently with Scan.
+func SetLenientScan(enabled bool) {
+	lenientScan.Store(enabled)
+}
+
+// LenientScan reports whether lenient scanning is enabled
+func LenientScan() bool {
+	return lenientScan.Load()
+}
+
+// validPercentEncoding reports whether every '%' in s introduces a two digit
+// hexadecimal escape, as required for CAIP-10 addresses and CAIP-19 references
+func validPercentEncoding(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return false
+		}
+
+		i += 2
+	}
+
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
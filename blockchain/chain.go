@@ -19,8 +19,11 @@ type ChainId struct {
 }
 
 var (
-	chainNamespaceRegex = regexp.MustCompile("[-a-z0-9]{3,8}")
-	chainReferenceRegex = regexp.MustCompile("[-a-zA-Z0-9]{1,32}")
+	chainNamespaceRegex = regexp.MustCompile("^[-a-z0-9]{3,8}$")
+	chainReferenceRegex = regexp.MustCompile("^[-_a-zA-Z0-9]{1,32}$")
+
+	legacyChainNamespaceRegex = regexp.MustCompile("[-a-z0-9]{3,8}")
+	legacyChainReferenceRegex = regexp.MustCompile("[-a-zA-Z0-9]{1,32}")
 )
 
 func NewChainId(namespace, reference string) (ChainId, error) {
@@ -41,9 +44,35 @@ func (c ChainId) validate() error {
 		return errors.New("chain reference does not match spec")
 	}
 
+	return c.validateNamespace()
+}
+
+// validateNamespace applies the validator registered for the chain namespace
+func (c ChainId) validateNamespace() error {
+	if validator, ok := chainNamespaceValidator(c.Namespace); ok {
+		if err := validator(c.Reference); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// validateLegacy validates the chain id against the unanchored patterns used
+// before strict CAIP-2 validation was introduced and the validator registered
+// for its namespace
+func (c ChainId) validateLegacy() error {
+	if ok := legacyChainNamespaceRegex.Match([]byte(c.Namespace)); !ok {
+		return errors.New("chain namespace does not match spec")
+	}
+
+	if ok := legacyChainReferenceRegex.Match([]byte(c.Reference)); !ok {
+		return errors.New("chain reference does not match spec")
+	}
+
+	return c.validateNamespace()
+}
+
 // String returns the string form of chain id, namespace:reference
 func (c ChainId) String() string {
 	return c.Namespace + ":" + c.Reference
@@ -51,16 +80,26 @@ func (c ChainId) String() string {
 
 // Parse parses a string into a chain id from the string form, namespace:reference
 func (c *ChainId) Parse(s string) error {
+	return c.parse(s, true)
+}
+
+func (c *ChainId) parse(s string, strict bool) error {
 	split := strings.SplitN(s, ":", 2)
 	if len(split) != 2 {
 		return fmt.Errorf("invalid chain id: %s", s)
 	}
 
-	*c = ChainId{split[0], split[1]}
-	if err := c.validate(); err != nil {
+	cID := ChainId{split[0], split[1]}
+	validate := cID.validate
+	if !strict {
+		validate = cID.validateLegacy
+	}
+
+	if err := validate(); err != nil {
 		return err
 	}
 
+	*c = cID
 	return nil
 }
 
@@ -161,7 +200,7 @@ func (c *ChainId) Scan(src interface{}) error {
 		return nil
 	}
 
-	if err := c.Parse(i.String); err != nil {
+	if err := c.parse(i.String, !LenientScan()); err != nil {
 		return err
 	}
 
@@ -174,10 +213,21 @@ func (c ChainId) MarshalGQL(w io.Writer) {
 
 func (c *ChainId) UnmarshalGQL(v interface{}) error {
 	if id, ok := v.(string); ok {
-		if err := c.Parse(id); err != nil {
-			return fmt.Errorf("unmarshalling account id: %w", err)
+		if err := unmarshalGQLId(id, c.Parse); err != nil {
+			return fmt.Errorf("unmarshalling chain id: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// unmarshalGQLId parses a GraphQL id, falling back to its lowercase form as
+// MarshalGQL writes ids in uppercase and namespaces are lowercase
+func unmarshalGQLId(id string, parse func(string) error) error {
+	err := parse(id)
+	if err != nil && parse(strings.ToLower(id)) == nil {
+		return nil
+	}
+
+	return err
+}
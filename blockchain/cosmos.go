@@ -0,0 +1,249 @@
+package blockchain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/bech32"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+// CosmosKeyType identifies how a cosmos chain derives account addresses from
+// public keys
+type CosmosKeyType string
+
+const (
+	// CosmosSecp256k1 addresses are the RIPEMD-160 hash of the SHA-256 hash of
+	// the compressed key
+	CosmosSecp256k1 CosmosKeyType = "secp256k1"
+	// CosmosEthSecp256k1 addresses are the last 20 bytes of the Keccak-256 hash
+	// of the uncompressed key, as on ethermint chains such as injective and evmos
+	CosmosEthSecp256k1 CosmosKeyType = "eth_secp256k1"
+)
+
+type cosmosChain struct {
+	prefix  string
+	keyType CosmosKeyType
+}
+
+var (
+	cosmosChainsMu sync.RWMutex
+	// cosmosChains maps cosmos chain references to their account address
+	// Bech32 human readable part and key type. Chain ids with a revision suffix
+	// such as cosmoshub-4 are also matched by their base name.
+	cosmosChains = map[string]cosmosChain{
+		"cosmoshub":     {"cosmos", CosmosSecp256k1},
+		"theta-testnet": {"cosmos", CosmosSecp256k1},
+		"osmosis":       {"osmo", CosmosSecp256k1},
+		"osmo-test":     {"osmo", CosmosSecp256k1},
+		"juno":          {"juno", CosmosSecp256k1},
+		"stargaze":      {"stars", CosmosSecp256k1},
+		"akashnet":      {"akash", CosmosSecp256k1},
+		"axelar-dojo":   {"axelar", CosmosSecp256k1},
+		"noble":         {"noble", CosmosSecp256k1},
+		"grand":         {"noble", CosmosSecp256k1},
+		"dydx-mainnet":  {"dydx", CosmosSecp256k1},
+		"celestia":      {"celestia", CosmosSecp256k1},
+		"injective":     {"inj", CosmosEthSecp256k1},
+		// CAIP-5 references evmos_9001-2 and evmos_9000-4 by their hash
+		"hashed-95094315a9d6cf2a":  {"evmos", CosmosEthSecp256k1},
+		"hashed-e7a2a202849099cd":  {"evmos", CosmosEthSecp256k1},
+		"kaiyo":                    {"kujira", CosmosSecp256k1},
+		"secret":                   {"secret", CosmosSecp256k1},
+		"stride":                   {"stride", CosmosSecp256k1},
+		"neutron":                  {"neutron", CosmosSecp256k1},
+		"phoenix":                  {"terra", CosmosSecp256k1},
+		"crypto-org-chain-mainnet": {"cro", CosmosSecp256k1},
+	}
+
+	cosmosRevisionRegex = regexp.MustCompile("-[0-9]+$")
+	ibcDenomHashRegex   = regexp.MustCompile("^[0-9A-F]{64}$")
+)
+
+// RegisterCosmosPrefix registers the Bech32 account prefix used by a cosmos
+// chain reference whose addresses use secp256k1 keys, replacing any prefix
+// already registered for it
+func RegisterCosmosPrefix(reference, prefix string) {
+	RegisterCosmosChain(reference, prefix, CosmosSecp256k1)
+}
+
+// RegisterCosmosChain registers the Bech32 account prefix and key type used by
+// a cosmos chain reference, replacing any already registered for it
+func RegisterCosmosChain(reference, prefix string, keyType CosmosKeyType) {
+	cosmosChainsMu.Lock()
+	defer cosmosChainsMu.Unlock()
+
+	cosmosChains[reference] = cosmosChain{prefix: prefix, keyType: keyType}
+}
+
+// UnregisterCosmosChain removes the account prefix and key type registered for
+// a cosmos chain reference
+func UnregisterCosmosChain(reference string) {
+	cosmosChainsMu.Lock()
+	defer cosmosChainsMu.Unlock()
+
+	delete(cosmosChains, reference)
+}
+
+func lookupCosmosChain(reference string) (cosmosChain, bool) {
+	cosmosChainsMu.RLock()
+	defer cosmosChainsMu.RUnlock()
+
+	if chain, ok := cosmosChains[reference]; ok {
+		return chain, true
+	}
+
+	chain, ok := cosmosChains[cosmosRevisionRegex.ReplaceAllString(reference, "")]
+	return chain, ok
+}
+
+func cosmosPrefix(reference string) (string, bool) {
+	chain, ok := lookupCosmosChain(reference)
+	return chain.prefix, ok
+}
+
+// cosmosMaxMemo is the default maximum length in bytes of a transaction memo
+// in the Cosmos SDK
+const cosmosMaxMemo = 256
+
+// normaliseCosmosMemo validates a transaction memo, which exchanges use to
+// identify the customer of a deposit
+func normaliseCosmosMemo(_ ChainId, memo Memo) (Memo, error) {
+	if memo.Type != MemoText {
+		return Memo{}, fmt.Errorf("cosmos memos must be of type %s, got %s", MemoText, memo.Type)
+	}
+
+	return normaliseMemoText("cosmos", memo, cosmosMaxMemo)
+}
+
+// normaliseCosmosAddress validates a Bech32 account address, checking its
+// prefix when one is registered for the chain, and returns it in lowercase
+func normaliseCosmosAddress(chainId ChainId, address string) (string, error) {
+	hrp, _, err := decodeCosmosAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	if prefix, ok := cosmosPrefix(chainId.Reference); ok && hrp != prefix {
+		return "", fmt.Errorf("cosmos address prefix %s does not match chain %s: %s", hrp, chainId.Reference, address)
+	}
+
+	return strings.ToLower(address), nil
+}
+
+func decodeCosmosAddress(address string) (string, []byte, error) {
+	hrp, data, variant, err := bech32.Decode(address, bech32.MaxLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid cosmos address %s: %w", address, err)
+	}
+
+	if variant != bech32.Bech32 {
+		return "", nil, fmt.Errorf("invalid cosmos address checksum variant: %s", address)
+	}
+
+	key, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid cosmos address %s: %w", address, err)
+	}
+
+	if len(key) != 20 && len(key) != 32 {
+		return "", nil, fmt.Errorf("invalid cosmos address length: %s", address)
+	}
+
+	return hrp, key, nil
+}
+
+// deriveCosmosAddress derives the address of a secp256k1 public key, the Bech32
+// encoding of the key hash given by the chain's key type with its prefix
+func deriveCosmosAddress(chainId ChainId, publicKey []byte) (string, error) {
+	chain, ok := lookupCosmosChain(chainId.Reference)
+	if !ok {
+		return "", fmt.Errorf("unknown cosmos address prefix for chain %s", chainId)
+	}
+
+	key, err := parseSecp256k1PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	var hash []byte
+	switch chain.keyType {
+	case CosmosSecp256k1:
+		hash = hashes.Hash160(key.SerializeCompressed())
+	case CosmosEthSecp256k1:
+		hash = hashes.Keccak256(key.SerializeUncompressed()[1:])[12:]
+	default:
+		return "", fmt.Errorf("cosmos key type %s of chain %s: %w", chain.keyType, chainId, errors.ErrUnsupported)
+	}
+
+	data, err := bech32.ConvertBits(hash, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32.Encode(chain.prefix, data, bech32.Bech32)
+}
+
+// ConvertCosmosAccount returns the account id of the same key on another cosmos
+// chain, re-encoding the address with the target chain's Bech32 prefix. Chains
+// with different key types derive unrelated addresses and cannot be converted
+// between.
+func ConvertCosmosAccount(account AccountId, target ChainId) (AccountId, error) {
+	if account.ChainId.Namespace != "cosmos" || target.Namespace != "cosmos" {
+		return AccountId{}, fmt.Errorf("cannot convert account %s to chain %s", account, target)
+	}
+
+	chain, ok := lookupCosmosChain(target.Reference)
+	if !ok {
+		return AccountId{}, fmt.Errorf("unknown cosmos address prefix for chain %s", target)
+	}
+
+	if source, ok := lookupCosmosChain(account.ChainId.Reference); ok && source.keyType != chain.keyType {
+		return AccountId{}, fmt.Errorf("cannot convert %s account %s to %s chain %s: %w", source.keyType, account, chain.keyType, target, errors.ErrUnsupported)
+	}
+
+	_, key, err := decodeCosmosAddress(account.Address)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	data, err := bech32.ConvertBits(key, 8, 5, true)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	address, err := bech32.Encode(chain.prefix, data, bech32.Bech32)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	return NewAccountId(target, address)
+}
+
+// normaliseIBCReference validates that an asset reference is the uppercase hex
+// SHA-256 hash of an IBC denom trace
+func normaliseIBCReference(_ ChainId, reference string) (string, error) {
+	if !ibcDenomHashRegex.MatchString(reference) {
+		return "", fmt.Errorf("ibc reference must be an uppercase hex denom hash: %s", reference)
+	}
+
+	return reference, nil
+}
+
+// NewIBCAsset creates the asset id of an IBC token from its denom hash
+func NewIBCAsset(chainId ChainId, hash string) (AssetId, error) {
+	return NewAssetId(chainId, "ibc", hash)
+}
+
+// normaliseCosmosHash validates a 32 byte hex transaction hash and returns the
+// uppercase form used by tendermint
+func normaliseCosmosHash(_ ChainId, hash string) (string, error) {
+	if !hex32Regex.MatchString(hash) {
+		return "", fmt.Errorf("cosmos transaction hash must be 32 byte hex: %s", hash)
+	}
+
+	return strings.ToUpper(hash), nil
+}
@@ -0,0 +1,264 @@
+package blockchain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MemoType is the kind of value a deposit memo holds
+type MemoType string
+
+const (
+	// MemoText is a free form text memo or comment
+	MemoText MemoType = "text"
+	// MemoId is an unsigned integer memo, such as an XRP Ledger destination tag
+	MemoId MemoType = "id"
+	// MemoHash is a 32 byte hex memo
+	MemoHash MemoType = "hash"
+)
+
+// Memo is the memo or destination tag identifying the recipient of a deposit
+// to a shared address
+type Memo struct {
+	Type  MemoType
+	Value string
+}
+
+var memoHashRegex = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+
+// String returns the string form of the memo, type:value
+func (m Memo) String() string {
+	return string(m.Type) + ":" + m.Value
+}
+
+// normaliseMemoId validates an id memo that fits in a number of bits and returns
+// it without leading zeros
+func normaliseMemoId(namespace string, memo Memo, bits int) (Memo, error) {
+	id, err := strconv.ParseUint(memo.Value, 10, bits)
+	if err != nil {
+		return Memo{}, fmt.Errorf("%s id memo must be a %d bit unsigned integer: %s", namespace, bits, memo.Value)
+	}
+
+	return Memo{MemoId, strconv.FormatUint(id, 10)}, nil
+}
+
+// normaliseMemoText validates a text memo of at most a number of bytes
+func normaliseMemoText(namespace string, memo Memo, maxBytes int) (Memo, error) {
+	if !utf8.ValidString(memo.Value) || len(memo.Value) > maxBytes {
+		return Memo{}, fmt.Errorf("%s text memo must be valid UTF-8 of at most %d bytes", namespace, maxBytes)
+	}
+
+	return memo, nil
+}
+
+// normaliseMemoHash validates a 32 byte hex hash memo and returns it in
+// lowercase
+func normaliseMemoHash(namespace string, memo Memo) (Memo, error) {
+	if !memoHashRegex.MatchString(memo.Value) {
+		return Memo{}, fmt.Errorf("%s hash memo must be 32 byte hex: %s", namespace, memo.Value)
+	}
+
+	return Memo{MemoHash, strings.ToLower(memo.Value)}, nil
+}
+
+// DepositAddress is an account id with an optional memo, which is required to
+// credit deposits to shared addresses on chains such as XRP Ledger, Stellar and
+// TON to the right customer
+type DepositAddress struct {
+	AccountId AccountId
+	// Memo is the memo deposits must carry, or nil if the address is not shared
+	Memo *Memo
+}
+
+// NewDepositAddress creates a deposit address, validating the memo against the
+// namespace of the account's chain
+func NewDepositAddress(accountId AccountId, memo *Memo) (DepositAddress, error) {
+	d := DepositAddress{accountId, memo}
+	if err := d.normalise(); err != nil {
+		return DepositAddress{}, err
+	}
+
+	return d, nil
+}
+
+func (d *DepositAddress) normalise() error {
+	if d.Memo == nil {
+		return nil
+	}
+
+	if d.Memo.Value == "" {
+		return fmt.Errorf("deposit address memo must not be empty")
+	}
+
+	normaliser, ok := memoNamespaceNormaliser(d.AccountId.ChainId.Namespace)
+	if !ok {
+		return fmt.Errorf("memos are not supported in namespace %s", d.AccountId.ChainId.Namespace)
+	}
+
+	memo, err := normaliser(d.AccountId.ChainId, *d.Memo)
+	if err != nil {
+		return err
+	}
+
+	d.Memo = &memo
+	return nil
+}
+
+// String returns the string form of deposit address,
+// chain_namespace:chain_reference:address#memo_type:memo_value, or the
+// account id if there is no memo
+func (d DepositAddress) String() string {
+	if d.Memo == nil {
+		return d.AccountId.String()
+	}
+
+	return d.AccountId.String() + "#" + d.Memo.String()
+}
+
+// Equal reports whether two deposit addresses credit the same account and memo
+func (d DepositAddress) Equal(other DepositAddress) bool {
+	if !d.AccountId.Equal(other.AccountId) || (d.Memo == nil) != (other.Memo == nil) {
+		return false
+	}
+
+	return d.Memo == nil || *d.Memo == *other.Memo
+}
+
+// Parse parses a string into a deposit address from the string form,
+// chain_namespace:chain_reference:address#memo_type:memo_value
+func (d *DepositAddress) Parse(s string) error {
+	return d.parse(s, true)
+}
+
+func (d *DepositAddress) parse(s string, strict bool) error {
+	accountStr, memoStr, hasMemo := strings.Cut(s, "#")
+
+	var dAddr DepositAddress
+	if err := dAddr.AccountId.parse(accountStr, strict); err != nil {
+		return err
+	}
+
+	if hasMemo {
+		memoType, value, ok := strings.Cut(memoStr, ":")
+		if !ok {
+			return fmt.Errorf("invalid deposit address memo: %s", s)
+		}
+		dAddr.Memo = &Memo{MemoType(memoType), value}
+	}
+
+	// Memos are validated even when scanning leniently
+	if err := dAddr.normalise(); err != nil {
+		return err
+	}
+
+	*d = dAddr
+	return nil
+}
+
+// MustParse parses a string into a deposit address from the string form,
+// chain_namespace:chain_reference:address#memo_type:memo_value and panics if
+// there is an error
+func (d *DepositAddress) MustParse(s string) {
+	if err := d.Parse(s); err != nil {
+		panic(err)
+	}
+}
+
+// ParseDepositAddress parses a string into a deposit address from the string
+// form, chain_namespace:chain_reference:address#memo_type:memo_value
+func ParseDepositAddress(s string) (DepositAddress, error) {
+	var d DepositAddress
+	err := d.Parse(s)
+	if err != nil {
+		return d, err
+	}
+
+	return d, nil
+}
+
+// MustParseDepositAddress parses a string into a deposit address from the
+// string form, chain_namespace:chain_reference:address#memo_type:memo_value
+// and panics if there is an error
+func MustParseDepositAddress(s string) DepositAddress {
+	var d DepositAddress
+	d.MustParse(s)
+	return d
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for XML
+// deserialization
+func (d *DepositAddress) UnmarshalText(data []byte) error {
+	return d.Parse(string(data))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for XML
+// serialization
+func (d DepositAddress) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *DepositAddress) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	// Text memos may contain characters that are escaped in JSON strings
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("error decoding string '%s': %s", data, err)
+	}
+
+	return d.Parse(str)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DepositAddress) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *DepositAddress) UnmarshalProto(pb string) error {
+	return d.Parse(pb)
+}
+
+func (d DepositAddress) MarshalProto() (string, error) {
+	return d.String(), nil
+}
+
+func (d DepositAddress) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *DepositAddress) Scan(src interface{}) error {
+	var i sql.NullString
+	if err := i.Scan(src); err != nil {
+		return fmt.Errorf("scanning deposit address: %w", err)
+	}
+
+	if !i.Valid {
+		return nil
+	}
+
+	return d.parse(i.String, !LenientScan())
+}
+
+func (d DepositAddress) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(d.String()))
+}
+
+func (d *DepositAddress) UnmarshalGQL(v interface{}) error {
+	if id, ok := v.(string); ok {
+		if err := d.Parse(id); err != nil {
+			return fmt.Errorf("unmarshalling deposit address: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package blockchain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/util"
+)
+
+// HardenedKeyStart is the index of the first hardened child key
+const HardenedKeyStart uint32 = 0x80000000
+
+// DerivationPath is a BIP-32 key derivation path from a master key, with
+// hardened components offset by HardenedKeyStart
+type DerivationPath []uint32
+
+// NewBIP44Path creates the BIP-44 path m/44'/coin_type'/account'/change/index of
+// an address on a chain, using the derivation coin type of the chain
+func NewBIP44Path(chainId ChainId, account, change, index uint32) (DerivationPath, error) {
+	coinType, err := ChainCoinType(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	if account >= HardenedKeyStart || change >= HardenedKeyStart || index >= HardenedKeyStart {
+		return nil, fmt.Errorf("bip44 path components must be less than %d", HardenedKeyStart)
+	}
+
+	return DerivationPath{44 + HardenedKeyStart, coinType + HardenedKeyStart, account + HardenedKeyStart, change, index}, nil
+}
+
+// derivationCoinTypes maps chain namespaces whose chains all derive keys with
+// the same coin type, whatever their native asset, to that coin type
+var derivationCoinTypes = map[string]uint32{
+	"eip155": 60,
+	"solana": 501,
+	"tron":   195,
+}
+
+// ChainCoinType returns the SLIP-44 coin type used in the BIP-44 derivation
+// paths of a chain. This is not always the coin type of the chain's native
+// asset: every eip155 chain derives keys with the coin type of ether, and
+// bitcoin test networks with the testnet coin type. Other chains use the coin
+// type of their native asset.
+func ChainCoinType(chainId ChainId) (uint32, error) {
+	if coinType, ok := derivationCoinTypes[chainId.Namespace]; ok {
+		return coinType, nil
+	}
+
+	if chainId.Namespace == "bip122" {
+		if network, ok := bitcoinNetworks[chainId.Reference]; ok {
+			return network.coinType, nil
+		}
+	}
+
+	asset, err := NativeAsset(chainId)
+	if err != nil {
+		return 0, err
+	}
+
+	coinType, err := strconv.ParseUint(asset.Reference, 10, 31)
+	if err != nil || asset.Namespace != "slip44" {
+		return 0, fmt.Errorf("native asset %s is not a slip44 asset", asset)
+	}
+
+	return uint32(coinType), nil
+}
+
+// CoinType returns the coin type of a BIP-44 style path, the second component,
+// if it is hardened
+func (p DerivationPath) CoinType() (uint32, bool) {
+	if len(p) < 2 || p[1] < HardenedKeyStart {
+		return 0, false
+	}
+
+	return p[1] - HardenedKeyStart, true
+}
+
+// String returns the string form of derivation path, m/44'/60'/0'/0/5
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range p {
+		sb.WriteString("/")
+		if index >= HardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(index-HardenedKeyStart), 10))
+			sb.WriteString("'")
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+
+	return sb.String()
+}
+
+// Parse parses a string into a derivation path from the string form,
+// m/44'/60'/0'/0/5. Hardened components may be marked with ', h or H.
+func (p *DerivationPath) Parse(s string) error {
+	split := strings.Split(s, "/")
+	if split[0] != "m" {
+		return fmt.Errorf("derivation path must start with m: %s", s)
+	}
+
+	path := make(DerivationPath, 0, len(split)-1)
+	for _, component := range split[1:] {
+		hardened := false
+		if trimmed := strings.TrimRight(component, "'hH"); len(trimmed) == len(component)-1 {
+			component, hardened = trimmed, true
+		}
+
+		index, err := strconv.ParseUint(component, 10, 31)
+		if err != nil || (len(component) > 1 && component[0] == '0') {
+			return fmt.Errorf("invalid derivation path component %q: %s", component, s)
+		}
+
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		path = append(path, uint32(index))
+	}
+
+	*p = path
+	return nil
+}
+
+// MustParse parses a string into a derivation path from the string form,
+// m/44'/60'/0'/0/5 and panics if there is an error
+func (p *DerivationPath) MustParse(s string) {
+	if err := p.Parse(s); err != nil {
+		panic(err)
+	}
+}
+
+// ParseDerivationPath parses a string into a derivation path from the string
+// form, m/44'/60'/0'/0/5
+func ParseDerivationPath(s string) (DerivationPath, error) {
+	var p DerivationPath
+	err := p.Parse(s)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// MustParseDerivationPath parses a string into a derivation path from the
+// string form, m/44'/60'/0'/0/5 and panics if there is an error
+func MustParseDerivationPath(s string) DerivationPath {
+	var p DerivationPath
+	p.MustParse(s)
+	return p
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for XML
+// deserialization
+func (p *DerivationPath) UnmarshalText(data []byte) error {
+	return p.Parse(string(data))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for XML
+// serialization
+func (p DerivationPath) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *DerivationPath) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	str, err := util.UnquoteIfQuoted(data)
+	if err != nil {
+		return fmt.Errorf("error decoding string '%s': %s", data, err)
+	}
+
+	return p.Parse(str)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p DerivationPath) MarshalJSON() ([]byte, error) {
+	str := "\"" + p.String() + "\""
+
+	return []byte(str), nil
+}
+
+func (p DerivationPath) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p *DerivationPath) Scan(src interface{}) error {
+	var i sql.NullString
+	if err := i.Scan(src); err != nil {
+		return fmt.Errorf("scanning derivation path: %w", err)
+	}
+
+	if !i.Valid {
+		return nil
+	}
+
+	return p.Parse(i.String)
+}
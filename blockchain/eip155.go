@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+var (
+	eip155AddressRegex = regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
+	eip155HashRegex    = regexp.MustCompile("^0x[0-9a-fA-F]{64}$")
+)
+
+// normaliseEIP155Address validates a 20 byte hex address, verifying its EIP-55
+// checksum when it is mixed-case, and returns the lowercase form
+func normaliseEIP155Address(_ ChainId, address string) (string, error) {
+	if !eip155AddressRegex.MatchString(address) {
+		return "", fmt.Errorf("eip155 address must be 0x-prefixed 20 byte hex: %s", address)
+	}
+
+	lower := strings.ToLower(address)
+	if address != lower && address != "0x"+strings.ToUpper(lower[2:]) {
+		if address != checksumEIP155Address(lower) {
+			return "", fmt.Errorf("eip155 address has invalid EIP-55 checksum: %s", address)
+		}
+	}
+
+	return lower, nil
+}
+
+// checksumEIP155Address returns the EIP-55 mixed-case form of a lowercase
+// 0x-prefixed hex address
+func checksumEIP155Address(lower string) string {
+	hash := hashes.Keccak256([]byte(lower[2:]))
+	digest := hex.EncodeToString(hash)
+
+	checksummed := []byte(lower)
+	for i := 2; i < len(checksummed); i++ {
+		if checksummed[i] >= 'a' && digest[i-2] >= '8' {
+			checksummed[i] -= 'a' - 'A'
+		}
+	}
+
+	return string(checksummed)
+}
+
+// ChecksumAddress returns the EIP-55 mixed-case form of an eip155 account address
+func (a AccountId) ChecksumAddress() (string, error) {
+	if a.ChainId.Namespace != "eip155" {
+		return "", fmt.Errorf("checksum addresses are not supported in namespace %s", a.ChainId.Namespace)
+	}
+
+	lower, err := normaliseEIP155Address(a.ChainId, a.Address)
+	if err != nil {
+		return "", err
+	}
+
+	return checksumEIP155Address(lower), nil
+}
+
+// deriveEIP155Address derives the address of a secp256k1 public key, the last
+// 20 bytes of the Keccak-256 hash of the uncompressed key
+func deriveEIP155Address(_ ChainId, publicKey []byte) (string, error) {
+	key, err := parseSecp256k1PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return "0x" + hex.EncodeToString(hashes.Keccak256(key.SerializeUncompressed()[1:])[12:]), nil
+}
+
+// normaliseEIP155ContractReference validates that an asset reference is an EVM
+// contract address, verifying its EIP-55 checksum when it is mixed-case, and
+// returns the lowercase form
+func normaliseEIP155ContractReference(chainId ChainId, reference string) (string, error) {
+	return normaliseEIP155Address(chainId, reference)
+}
+
+// NewERC20Asset creates the asset id of an ERC-20 token contract
+func NewERC20Asset(chainId ChainId, contract string) (AssetId, error) {
+	return NewAssetId(chainId, "erc20", contract)
+}
+
+// NewERC721Asset creates the asset id of an ERC-721 collection contract.
+// Individual tokens are created with NewTokenAssetId.
+func NewERC721Asset(chainId ChainId, contract string) (AssetId, error) {
+	return NewAssetId(chainId, "erc721", contract)
+}
+
+// NewERC1155Asset creates the asset id of an ERC-1155 contract. Individual
+// tokens are created with NewTokenAssetId.
+func NewERC1155Asset(chainId ChainId, contract string) (AssetId, error) {
+	return NewAssetId(chainId, "erc1155", contract)
+}
+
+// normaliseEIP155Hash validates a 0x-prefixed 32 byte hex transaction hash and
+// returns the lowercase form
+func normaliseEIP155Hash(_ ChainId, hash string) (string, error) {
+	if !eip155HashRegex.MatchString(hash) {
+		return "", fmt.Errorf("eip155 transaction hash must be 0x-prefixed 32 byte hex: %s", hash)
+	}
+
+	return strings.ToLower(hash), nil
+}
@@ -0,0 +1,177 @@
+package blockchain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/util"
+)
+
+// EventId identifies an individual event within a transaction, such as an EVM
+// log or a Solana instruction, by its index
+type EventId struct {
+	TransactionId TransactionId
+	Index         uint32
+}
+
+func NewEventId(transactionId TransactionId, index uint32) (EventId, error) {
+	tID, err := transactionId.canonical(true)
+	if err != nil {
+		return EventId{}, err
+	}
+
+	return EventId{tID, index}, nil
+}
+
+// String returns the string form of event id, chain_namespace:chain_reference:hash:index
+func (e EventId) String() string {
+	return e.TransactionId.String() + ":" + strconv.FormatUint(uint64(e.Index), 10)
+}
+
+// Parse parses a string into a event id from the string form, chain_namespace:chain_reference:hash:index
+func (e *EventId) Parse(s string) error {
+	sep := strings.LastIndexByte(s, ':')
+	if sep < 0 {
+		return fmt.Errorf("invalid event id: %s", s)
+	}
+
+	index, err := strconv.ParseUint(s[sep+1:], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid event index: %s", s)
+	}
+
+	tID, err := ParseTransactionId(s[:sep])
+	if err != nil {
+		return err
+	}
+
+	*e = EventId{tID, uint32(index)}
+	return nil
+}
+
+// MustParse parses a string into a event id from the string form, chain_namespace:chain_reference:hash:index
+// and panics if there is an error
+func (e *EventId) MustParse(s string) {
+	if err := e.Parse(s); err != nil {
+		panic(err)
+	}
+}
+
+// ParseEventId parses a string into a event id from the string form, chain_namespace:chain_reference:hash:index
+func ParseEventId(s string) (EventId, error) {
+	var e EventId
+	err := e.Parse(s)
+	if err != nil {
+		return e, err
+	}
+
+	return e, nil
+}
+
+// MustParseEventId parses a string into a event id from the string form, chain_namespace:chain_reference:hash:index
+// and panics if there is an error
+func MustParseEventId(s string) EventId {
+	var e EventId
+	e.MustParse(s)
+	return e
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for XML
+// deserialization
+func (e *EventId) UnmarshalText(data []byte) error {
+	eventId, err := ParseEventId(string(data))
+	if err != nil {
+		return err
+	}
+	*e = eventId
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for XML
+// serialization
+func (e EventId) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (e *EventId) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	str, err := util.UnquoteIfQuoted(data)
+	if err != nil {
+		return fmt.Errorf("error decoding string '%s': %s", data, err)
+	}
+
+	eventId, err := ParseEventId(str)
+	if err != nil {
+		return err
+	}
+	*e = eventId
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e EventId) MarshalJSON() ([]byte, error) {
+	str := "\"" + e.String() + "\""
+
+	return []byte(str), nil
+}
+
+func (e *EventId) UnmarshalProto(pb string) error {
+	eventId, err := ParseEventId(pb)
+	if err != nil {
+		return err
+	}
+	*e = eventId
+	return nil
+}
+
+func (e EventId) MarshalProto() (string, error) {
+	return e.String(), nil
+}
+
+func (e EventId) Value() (driver.Value, error) {
+	tID, err := e.TransactionId.canonical(true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event id %s: %w", e, err)
+	}
+
+	return EventId{tID, e.Index}.String(), nil
+}
+
+func (e *EventId) Scan(src interface{}) error {
+	var i sql.NullString
+	if err := i.Scan(src); err != nil {
+		return fmt.Errorf("scanning event id: %w", err)
+	}
+
+	if !i.Valid {
+		return nil
+	}
+
+	if err := e.Parse(i.String); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e EventId) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(strings.ToUpper(e.String())))
+}
+
+func (e *EventId) UnmarshalGQL(v interface{}) error {
+	if id, ok := v.(string); ok {
+		if err := unmarshalGQLId(id, e.Parse); err != nil {
+			return fmt.Errorf("unmarshalling event id: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/types"
+)
+
+// ExplorerTemplates holds the block explorer URL templates of a chain. Templates
+// may contain the placeholders {address}, {reference}, {tokenId} and {hash},
+// which are replaced with the path escaped components of the identifier.
+type ExplorerTemplates struct {
+	// Chain is the URL of the explorer home page for the chain
+	Chain string `json:"chain,omitempty"`
+	// Account is the URL template of an account page, using {address}
+	Account string `json:"account,omitempty"`
+	// Token is the URL template of a fungible asset page, using {reference}
+	Token string `json:"token,omitempty"`
+	// NFT is the URL template of an individual token page, using {reference}
+	// and {tokenId}
+	NFT string `json:"nft,omitempty"`
+	// Transaction is the URL template of a transaction page, using {hash}
+	Transaction string `json:"transaction,omitempty"`
+}
+
+// ExplorerResolver generates block explorer URLs from the templates registered
+// with each chain, allowing the templates of individual chains to be overridden
+type ExplorerResolver struct {
+	overrides map[ChainId]ExplorerTemplates
+}
+
+// NewExplorerResolver creates an explorer resolver using the given templates in
+// place of the registered templates of the chains they are keyed by
+func NewExplorerResolver(overrides map[ChainId]ExplorerTemplates) *ExplorerResolver {
+	return &ExplorerResolver{overrides}
+}
+
+func (r *ExplorerResolver) templates(chainId ChainId) ExplorerTemplates {
+	if templates, ok := r.overrides[chainId]; ok {
+		return templates
+	}
+
+	info, _ := LookupChain(chainId)
+	return info.Explorer
+}
+
+// ChainURL returns the explorer home page of a chain
+func (r *ExplorerResolver) ChainURL(chainId ChainId) (types.URL, error) {
+	return expandExplorerTemplate(chainId, "chain", r.templates(chainId).Chain, nil)
+}
+
+// AccountURL returns the explorer page of an account
+func (r *ExplorerResolver) AccountURL(account AccountId) (types.URL, error) {
+	return expandExplorerTemplate(account.ChainId, "account", r.templates(account.ChainId).Account, map[string]string{
+		"address": account.Address,
+	})
+}
+
+// nonContractAssetNamespaces are the asset namespaces whose references are not
+// token contracts and so have no explorer token page
+var nonContractAssetNamespaces = []string{"slip44", "iso4217"}
+
+// AssetURL returns the explorer page of a token, or of an individual token when
+// the asset id has a token id. Native assets and fiat currencies have no token
+// page.
+func (r *ExplorerResolver) AssetURL(asset AssetId) (types.URL, error) {
+	if slices.Contains(nonContractAssetNamespaces, asset.Namespace) {
+		return types.URL{}, fmt.Errorf("no explorer url for %s asset %s: %w", asset.Namespace, asset, errors.ErrUnsupported)
+	}
+
+	templates := r.templates(asset.ChainId)
+	if asset.TokenId != "" {
+		return expandExplorerTemplate(asset.ChainId, "nft", templates.NFT, map[string]string{
+			"reference": asset.Reference,
+			"tokenId":   asset.TokenId,
+		})
+	}
+
+	return expandExplorerTemplate(asset.ChainId, "token", templates.Token, map[string]string{
+		"reference": asset.Reference,
+	})
+}
+
+// TransactionURL returns the explorer page of a transaction
+func (r *ExplorerResolver) TransactionURL(transaction TransactionId) (types.URL, error) {
+	return expandExplorerTemplate(transaction.ChainId, "transaction", r.templates(transaction.ChainId).Transaction, map[string]string{
+		"hash": transaction.Hash,
+	})
+}
+
+func expandExplorerTemplate(chainId ChainId, kind, template string, values map[string]string) (types.URL, error) {
+	if template == "" {
+		return types.URL{}, fmt.Errorf("no %s explorer url for chain %s: %w", kind, chainId, errors.ErrUnsupported)
+	}
+
+	replacements := make([]string, 0, len(values)*2)
+	for name, value := range values {
+		replacements = append(replacements, "{"+name+"}", url.PathEscape(value))
+	}
+
+	return types.Parse(strings.NewReplacer(replacements...).Replace(template))
+}
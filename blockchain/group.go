@@ -0,0 +1,214 @@
+package blockchain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// AssetGroup is a set of assets on different chains, or native and bridged
+// variants on the same chain, that represent the same underlying instrument
+type AssetGroup struct {
+	// Key is the canonical identifier of the group, e.g. usdc
+	Key    string    `json:"key"`
+	Name   string    `json:"name"`
+	Assets []AssetId `json:"assets"`
+}
+
+//go:embed groups.json
+var embeddedAssetGroups []byte
+
+var (
+	assetGroupsMu sync.RWMutex
+	assetGroups   = map[string]AssetGroup{}
+	// assetGroupKeys maps the index key of each grouped asset to its group key
+	assetGroupKeys = map[string]string{}
+
+	assetGroupKeyRegex = regexp.MustCompile("^[-.a-z0-9]{1,64}$")
+)
+
+func init() {
+	if err := LoadAssetGroups(bytes.NewReader(embeddedAssetGroups)); err != nil {
+		panic(fmt.Errorf("loading embedded asset groups: %w", err))
+	}
+}
+
+// RegisterAssetGroup adds an asset group to the registry, replacing any group
+// already registered with the same key. Assets that belong to another group are
+// moved to the new group.
+func RegisterAssetGroup(group AssetGroup) error {
+	if err := group.validate(); err != nil {
+		return err
+	}
+
+	assetGroupsMu.Lock()
+	defer assetGroupsMu.Unlock()
+
+	registerAssetGroup(group)
+	return nil
+}
+
+// LoadAssetGroups reads a JSON array of asset groups and adds them to the
+// registry, replacing any groups already registered with the same keys
+func LoadAssetGroups(r io.Reader) error {
+	var groups []AssetGroup
+	if err := json.NewDecoder(r).Decode(&groups); err != nil {
+		return fmt.Errorf("decoding asset groups: %w", err)
+	}
+
+	for _, group := range groups {
+		if err := group.validate(); err != nil {
+			return err
+		}
+	}
+
+	assetGroupsMu.Lock()
+	defer assetGroupsMu.Unlock()
+
+	for _, group := range groups {
+		registerAssetGroup(group)
+	}
+
+	return nil
+}
+
+func (g AssetGroup) validate() error {
+	if !assetGroupKeyRegex.MatchString(g.Key) {
+		return fmt.Errorf("asset group key does not match spec: %s", g.Key)
+	}
+
+	for _, asset := range g.Assets {
+		if !asset.IsFungible() {
+			return fmt.Errorf("asset group %s contains non-fungible asset %s", g.Key, asset)
+		}
+	}
+
+	return nil
+}
+
+// registerAssetGroup adds a group and indexes its assets, removing the assets of
+// any group it replaces and taking its assets from other groups. The caller must
+// hold assetGroupsMu.
+func registerAssetGroup(group AssetGroup) {
+	if previous, ok := assetGroups[group.Key]; ok {
+		for _, asset := range previous.Assets {
+			delete(assetGroupKeys, asset.String())
+		}
+	}
+
+	assets := make([]AssetId, 0, len(group.Assets))
+	for _, asset := range group.Assets {
+		index := asset.String()
+		key, ok := assetGroupKeys[index]
+		if ok && key == group.Key {
+			continue
+		}
+
+		if ok {
+			other := assetGroups[key]
+			other.Assets = slices.DeleteFunc(slices.Clone(other.Assets), func(a AssetId) bool {
+				return a.String() == index
+			})
+			assetGroups[key] = other
+		}
+
+		assetGroupKeys[index] = group.Key
+		assets = append(assets, asset)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].String() < assets[j].String()
+	})
+
+	group.Assets = assets
+	assetGroups[group.Key] = group
+}
+
+// UnregisterAssetGroup removes an asset group and the index entries of its
+// assets from the registry
+func UnregisterAssetGroup(key string) {
+	assetGroupsMu.Lock()
+	defer assetGroupsMu.Unlock()
+
+	group, ok := assetGroups[key]
+	if !ok {
+		return
+	}
+
+	for _, asset := range group.Assets {
+		delete(assetGroupKeys, asset.String())
+	}
+
+	delete(assetGroups, key)
+}
+
+// LookupAssetGroup returns the registered asset group with the given key
+func LookupAssetGroup(key string) (AssetGroup, bool) {
+	assetGroupsMu.RLock()
+	defer assetGroupsMu.RUnlock()
+
+	group, ok := assetGroups[key]
+	return group, ok
+}
+
+// AssetGroups returns all registered asset groups ordered by key
+func AssetGroups() []AssetGroup {
+	assetGroupsMu.RLock()
+	defer assetGroupsMu.RUnlock()
+
+	groups := make([]AssetGroup, 0, len(assetGroups))
+	for _, group := range assetGroups {
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+
+	return groups
+}
+
+// AssetGroupKey returns the key of the group an asset belongs to
+func AssetGroupKey(asset AssetId) (string, bool) {
+	assetGroupsMu.RLock()
+	defer assetGroupsMu.RUnlock()
+
+	key, ok := assetGroupKeys[asset.String()]
+	return key, ok
+}
+
+// EquivalentAssets returns the assets in the same group as an asset, including
+// the asset itself, or only the asset if it does not belong to a group
+func EquivalentAssets(asset AssetId) []AssetId {
+	assetGroupsMu.RLock()
+	defer assetGroupsMu.RUnlock()
+
+	key, ok := assetGroupKeys[asset.String()]
+	if !ok {
+		return []AssetId{asset}
+	}
+
+	return slices.Clone(assetGroups[key].Assets)
+}
+
+// Equivalent reports whether two assets are the same asset or belong to the same
+// asset group
+func (a AssetId) Equivalent(b AssetId) bool {
+	if a.String() == b.String() {
+		return true
+	}
+
+	key, ok := AssetGroupKey(a)
+	if !ok {
+		return false
+	}
+
+	other, ok := AssetGroupKey(b)
+	return ok && key == other
+}
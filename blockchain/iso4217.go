@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// FiatChainId is the pseudo chain that fiat currency assets are identified on,
+// so that a currency such as USD has the asset id fiat:0/iso4217:USD
+var FiatChainId = ChainId{"fiat", "0"}
+
+// Currency holds the ISO 4217 metadata of a fiat currency
+type Currency struct {
+	// Code is the alphabetic currency code, e.g. USD
+	Code string `json:"code"`
+	// Numeric is the numeric currency code, e.g. 840
+	Numeric uint16 `json:"numeric"`
+	// MinorUnits is the number of decimal places of the currency's minor unit
+	MinorUnits int32  `json:"minorUnits"`
+	Name       string `json:"name"`
+}
+
+//go:embed iso4217.json
+var embeddedCurrencies []byte
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]Currency{}
+
+	currencyCodeRegex = regexp.MustCompile("^[A-Z]{3}$")
+)
+
+func init() {
+	var table []Currency
+	if err := json.NewDecoder(bytes.NewReader(embeddedCurrencies)).Decode(&table); err != nil {
+		panic(fmt.Errorf("loading embedded currencies: %w", err))
+	}
+
+	for _, currency := range table {
+		currencies[currency.Code] = currency
+	}
+}
+
+// RegisterCurrency adds a currency to the registry, replacing any currency
+// already registered with the same code. The code must be three uppercase
+// letters and the minor units must not be negative.
+func RegisterCurrency(currency Currency) error {
+	if !currencyCodeRegex.MatchString(currency.Code) {
+		return fmt.Errorf("currency code must be three uppercase letters: %s", currency.Code)
+	}
+
+	if currency.MinorUnits < 0 {
+		return fmt.Errorf("currency %s has negative minor units", currency.Code)
+	}
+
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+
+	currencies[currency.Code] = currency
+	return nil
+}
+
+// UnregisterCurrency removes a currency from the registry
+func UnregisterCurrency(code string) {
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+
+	delete(currencies, code)
+}
+
+// LookupCurrency returns the registered metadata for a currency code
+func LookupCurrency(code string) (Currency, bool) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	currency, ok := currencies[code]
+	return currency, ok
+}
+
+// Currencies returns all registered currencies ordered by code
+func Currencies() []Currency {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	table := make([]Currency, 0, len(currencies))
+	for _, currency := range currencies {
+		table = append(table, currency)
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		return table[i].Code < table[j].Code
+	})
+
+	return table
+}
+
+// AssetId returns the asset id of the currency
+func (c Currency) AssetId() AssetId {
+	return AssetId{FiatChainId, "iso4217", c.Code, ""}
+}
+
+// normaliseISO4217Reference validates that an asset reference is a registered
+// currency code
+func normaliseISO4217Reference(_ ChainId, reference string) (string, error) {
+	if _, ok := LookupCurrency(reference); !ok {
+		return "", fmt.Errorf("unknown iso4217 currency code: %s", reference)
+	}
+
+	return reference, nil
+}
+
+// NewFiatAsset creates the asset id of a fiat currency from its code
+func NewFiatAsset(code string) (AssetId, error) {
+	return NewAssetId(FiatChainId, "iso4217", code)
+}
+
+// IsFiat reports whether the asset is a fiat currency
+func (a AssetId) IsFiat() bool {
+	return a.ChainId == FiatChainId && a.Namespace == "iso4217"
+}
+
+// Currency returns the registered metadata for a fiat currency asset
+func (a AssetId) Currency() (Currency, error) {
+	if !a.IsFiat() {
+		return Currency{}, fmt.Errorf("asset %s is not a fiat currency", a)
+	}
+
+	currency, ok := LookupCurrency(a.Reference)
+	if !ok {
+		return Currency{}, fmt.Errorf("unknown iso4217 currency code: %s", a.Reference)
+	}
+
+	return currency, nil
+}
+
+// NewFiatAmountFromMinorUnits creates an amount of a fiat currency from an
+// integer number of its minor units, e.g. cents
+func NewFiatAmountFromMinorUnits(code string, units *big.Int) (Amount, error) {
+	currency, ok := LookupCurrency(code)
+	if !ok {
+		return Amount{}, fmt.Errorf("unknown iso4217 currency code: %s", code)
+	}
+
+	return NewAmountFromBaseUnits(currency.AssetId(), units, currency.MinorUnits), nil
+}
+
+// MinorUnits returns a fiat amount as an integer number of the currency's minor
+// units, failing if the amount is more precise than a single minor unit
+func (a Amount) MinorUnits() (*big.Int, error) {
+	currency, err := a.Asset.Currency()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.BaseUnits(currency.MinorUnits)
+}
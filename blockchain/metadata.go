@@ -0,0 +1,195 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+)
+
+// AssetMetadata holds the display metadata of an asset
+type AssetMetadata struct {
+	Symbol   string
+	Name     string
+	Decimals int32
+	Logo     types.URL
+}
+
+// AssetMetadataProvider resolves the display metadata of assets. Providers
+// return an error wrapping errors.ErrNotFound for assets they do not know.
+type AssetMetadataProvider interface {
+	AssetMetadata(ctx context.Context, asset AssetId) (AssetMetadata, error)
+}
+
+// MemoryAssetMetadataProvider is an asset metadata provider backed by an
+// in-memory table
+type MemoryAssetMetadataProvider struct {
+	mu       sync.RWMutex
+	metadata map[string]AssetMetadata
+}
+
+// NewMemoryAssetMetadataProvider creates an in-memory asset metadata provider
+// holding the given metadata
+func NewMemoryAssetMetadataProvider(metadata map[AssetId]AssetMetadata) *MemoryAssetMetadataProvider {
+	p := &MemoryAssetMetadataProvider{metadata: make(map[string]AssetMetadata, len(metadata))}
+	for asset, m := range metadata {
+		p.metadata[asset.String()] = m
+	}
+
+	return p
+}
+
+// Set adds the metadata of an asset, replacing any metadata already held for it
+func (p *MemoryAssetMetadataProvider) Set(asset AssetId, metadata AssetMetadata) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metadata[asset.String()] = metadata
+}
+
+func (p *MemoryAssetMetadataProvider) AssetMetadata(_ context.Context, asset AssetId) (AssetMetadata, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	metadata, ok := p.metadata[asset.String()]
+	if !ok {
+		return AssetMetadata{}, fmt.Errorf("no metadata for asset %s: %w", asset, errors.ErrNotFound)
+	}
+
+	return metadata, nil
+}
+
+// tokenList is a token list in the Uniswap token list format,
+// https://github.com/Uniswap/token-lists
+type tokenList struct {
+	Name   string `json:"name"`
+	Tokens []struct {
+		ChainId  uint64 `json:"chainId"`
+		Address  string `json:"address"`
+		Name     string `json:"name"`
+		Symbol   string `json:"symbol"`
+		Decimals int32  `json:"decimals"`
+		LogoURI  string `json:"logoURI"`
+	} `json:"tokens"`
+}
+
+// NewTokenListAssetMetadataProvider creates an in-memory asset metadata provider
+// from a token list in the Uniswap token list format. Tokens are identified as
+// erc20 assets on the eip155 chain of their chain id.
+func NewTokenListAssetMetadataProvider(r io.Reader) (*MemoryAssetMetadataProvider, error) {
+	var list tokenList
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decoding token list: %w", err)
+	}
+
+	metadata := make(map[AssetId]AssetMetadata, len(list.Tokens))
+	for _, token := range list.Tokens {
+		chainId, err := NewChainId("eip155", strconv.FormatUint(token.ChainId, 10))
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", token.Symbol, err)
+		}
+
+		asset, err := NewERC20Asset(chainId, token.Address)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", token.Symbol, err)
+		}
+
+		var logo types.URL
+		if token.LogoURI != "" {
+			if logo, err = types.Parse(token.LogoURI); err != nil {
+				return nil, fmt.Errorf("token %s logo: %w", token.Symbol, err)
+			}
+		}
+
+		metadata[asset] = AssetMetadata{token.Symbol, token.Name, token.Decimals, logo}
+	}
+
+	return NewMemoryAssetMetadataProvider(metadata), nil
+}
+
+// NewFileAssetMetadataProvider creates an in-memory asset metadata provider from
+// a token list file in the Uniswap token list format
+func NewFileAssetMetadataProvider(path string) (*MemoryAssetMetadataProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening token list: %w", err)
+	}
+	defer f.Close()
+
+	return NewTokenListAssetMetadataProvider(f)
+}
+
+type cachedAssetMetadata struct {
+	metadata AssetMetadata
+	err      error
+	expires  time.Time
+}
+
+// CachingAssetMetadataProvider is an asset metadata provider that caches the
+// results of another provider, including assets that were not found
+type CachingAssetMetadataProvider struct {
+	provider AssetMetadataProvider
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedAssetMetadata
+}
+
+// NewCachingAssetMetadataProvider creates a provider caching the results of
+// another provider for the given time to live, or forever if ttl is zero
+func NewCachingAssetMetadataProvider(provider AssetMetadataProvider, ttl time.Duration) *CachingAssetMetadataProvider {
+	return &CachingAssetMetadataProvider{
+		provider: provider,
+		ttl:      ttl,
+		cache:    map[string]cachedAssetMetadata{},
+	}
+}
+
+func (p *CachingAssetMetadataProvider) AssetMetadata(ctx context.Context, asset AssetId) (AssetMetadata, error) {
+	key := asset.String()
+
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+
+	if ok && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.metadata, cached.err
+	}
+
+	metadata, err := p.provider.AssetMetadata(ctx, asset)
+	// Only successes and not found results are cached so that transient
+	// failures of the underlying provider are retried
+	if err != nil && !stderrors.Is(err, errors.ErrNotFound) {
+		return AssetMetadata{}, err
+	}
+
+	cached = cachedAssetMetadata{metadata: metadata, err: err}
+	if p.ttl > 0 {
+		cached.expires = time.Now().Add(p.ttl)
+	}
+
+	p.mu.Lock()
+	p.cache[key] = cached
+	p.mu.Unlock()
+
+	return metadata, err
+}
+
+// FormatAmount formats an amount for display with the symbol of its asset and
+// as many decimal places as the asset has, e.g. 1.500000 USDC
+func FormatAmount(ctx context.Context, provider AssetMetadataProvider, amount Amount) (string, error) {
+	metadata, err := provider.AssetMetadata(ctx, amount.Asset)
+	if err != nil {
+		return "", err
+	}
+
+	return amount.Quantity.StringFixed(metadata.Decimals) + " " + metadata.Symbol, nil
+}
@@ -0,0 +1,278 @@
+package blockchain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// ChainReferenceValidator validates the reference component of a chain id
+// belonging to a single CAIP-2 namespace
+type ChainReferenceValidator func(reference string) error
+
+var (
+	chainNamespacesMu sync.RWMutex
+	chainNamespaces   = map[string]ChainReferenceValidator{
+		"eip155":   regexReferenceValidator("eip155", regexp.MustCompile("^[1-9][0-9]{0,31}$")),
+		"bip122":   regexReferenceValidator("bip122", regexp.MustCompile("^[0-9a-f]{32}$")),
+		"cosmos":   regexReferenceValidator("cosmos", regexp.MustCompile("^[-a-zA-Z0-9]{1,32}$")),
+		"solana":   regexReferenceValidator("solana", regexp.MustCompile("^[1-9A-HJ-NP-Za-km-z]{32}$")),
+		"polkadot": regexReferenceValidator("polkadot", regexp.MustCompile("^[0-9a-f]{32}$")),
+		"lip9":     regexReferenceValidator("lip9", regexp.MustCompile("^[0-9a-f]{16}$")),
+		"fiat":     regexReferenceValidator("fiat", regexp.MustCompile("^0$")),
+	}
+)
+
+// RegisterChainNamespace registers a validator for the references of a chain
+// namespace, replacing any validator already registered for it. Chain ids in
+// namespaces without a registered validator are only checked against the
+// generic CAIP-2 grammar.
+func RegisterChainNamespace(namespace string, validator ChainReferenceValidator) {
+	chainNamespacesMu.Lock()
+	defer chainNamespacesMu.Unlock()
+
+	chainNamespaces[namespace] = validator
+}
+
+// UnregisterChainNamespace removes the reference validator of a chain namespace
+func UnregisterChainNamespace(namespace string) {
+	chainNamespacesMu.Lock()
+	defer chainNamespacesMu.Unlock()
+
+	delete(chainNamespaces, namespace)
+}
+
+func chainNamespaceValidator(namespace string) (ChainReferenceValidator, bool) {
+	chainNamespacesMu.RLock()
+	defer chainNamespacesMu.RUnlock()
+
+	validator, ok := chainNamespaces[namespace]
+	return validator, ok
+}
+
+func regexReferenceValidator(namespace string, regex *regexp.Regexp) ChainReferenceValidator {
+	return func(reference string) error {
+		if !regex.MatchString(reference) {
+			return fmt.Errorf("chain reference does not match %s spec: %s", namespace, reference)
+		}
+
+		return nil
+	}
+}
+
+// AddressNormaliser validates an account address on a chain belonging to a
+// single CAIP-2 namespace and returns the canonical form of the address
+type AddressNormaliser func(chainId ChainId, address string) (string, error)
+
+var (
+	accountNamespacesMu sync.RWMutex
+	accountNamespaces   = map[string]AddressNormaliser{
+		"eip155": normaliseEIP155Address,
+		"bip122": normaliseBIP122Address,
+		"cosmos": normaliseCosmosAddress,
+		"solana": normaliseSolanaAddress,
+		"tron":   normaliseTronAddress,
+	}
+)
+
+// RegisterAccountNamespace registers a normaliser for the account addresses of
+// a chain namespace, replacing any normaliser already registered for it.
+// Addresses in namespaces without a registered normaliser are only checked
+// against the generic CAIP-10 grammar and are kept as given.
+func RegisterAccountNamespace(namespace string, normaliser AddressNormaliser) {
+	accountNamespacesMu.Lock()
+	defer accountNamespacesMu.Unlock()
+
+	accountNamespaces[namespace] = normaliser
+}
+
+func accountNamespaceNormaliser(namespace string) (AddressNormaliser, bool) {
+	accountNamespacesMu.RLock()
+	defer accountNamespacesMu.RUnlock()
+
+	normaliser, ok := accountNamespaces[namespace]
+	return normaliser, ok
+}
+
+// AssetNamespace describes where a CAIP-19 asset namespace may be used and how
+// its references are normalised
+type AssetNamespace struct {
+	// ChainNamespaces lists the chain namespaces the asset namespace is legal
+	// in, or is empty if it is legal on every chain
+	ChainNamespaces []string
+	// NormaliseReference validates an asset reference on a chain and returns
+	// its canonical form. References are kept as given if it is nil.
+	NormaliseReference func(chainId ChainId, reference string) (string, error)
+	// NonFungible is set for namespaces of non-fungible or semi-fungible
+	// collections, whose asset ids may identify an individual token
+	NonFungible bool
+}
+
+var (
+	assetNamespacesMu sync.RWMutex
+	assetNamespaces   = map[string]AssetNamespace{
+		"slip44":  {nil, normaliseSLIP44Reference, false},
+		"erc20":   {[]string{"eip155"}, normaliseEIP155ContractReference, false},
+		"erc721":  {[]string{"eip155"}, normaliseEIP155ContractReference, true},
+		"erc1155": {[]string{"eip155"}, normaliseEIP155ContractReference, true},
+		"spl":     {[]string{"solana"}, normaliseSPLReference, false},
+		"ibc":     {[]string{"cosmos"}, normaliseIBCReference, false},
+		"iso4217": {[]string{"fiat"}, normaliseISO4217Reference, false},
+	}
+)
+
+// RegisterAssetNamespace registers an asset namespace, replacing any asset
+// namespace already registered under the same name. Asset ids in namespaces
+// that are not registered are only checked against the generic CAIP-19 grammar.
+func RegisterAssetNamespace(namespace string, assetNamespace AssetNamespace) {
+	assetNamespacesMu.Lock()
+	defer assetNamespacesMu.Unlock()
+
+	assetNamespaces[namespace] = assetNamespace
+}
+
+// UnregisterAssetNamespace removes an asset namespace from the registry
+func UnregisterAssetNamespace(namespace string) {
+	assetNamespacesMu.Lock()
+	defer assetNamespacesMu.Unlock()
+
+	delete(assetNamespaces, namespace)
+}
+
+func lookupAssetNamespace(namespace string) (AssetNamespace, bool) {
+	assetNamespacesMu.RLock()
+	defer assetNamespacesMu.RUnlock()
+
+	assetNamespace, ok := assetNamespaces[namespace]
+	return assetNamespace, ok
+}
+
+// normalise checks that the asset namespace is legal on the chain of an asset
+// id and that only non-fungible asset ids have a token id, returning the asset
+// id with its reference in canonical form
+func (n AssetNamespace) normalise(a AssetId) (AssetId, error) {
+	if len(n.ChainNamespaces) > 0 && !slices.Contains(n.ChainNamespaces, a.ChainId.Namespace) {
+		return AssetId{}, fmt.Errorf("asset namespace %s is not supported on chain namespace %s", a.Namespace, a.ChainId.Namespace)
+	}
+
+	if a.TokenId != "" && !n.NonFungible {
+		return AssetId{}, fmt.Errorf("asset namespace %s does not have token ids", a.Namespace)
+	}
+
+	if n.NormaliseReference != nil {
+		reference, err := n.NormaliseReference(a.ChainId, a.Reference)
+		if err != nil {
+			return AssetId{}, err
+		}
+		a.Reference = reference
+	}
+
+	return a, nil
+}
+
+// HashNormaliser validates a transaction hash on a chain belonging to a single
+// CAIP-2 namespace and returns the canonical form of the hash
+type HashNormaliser func(chainId ChainId, hash string) (string, error)
+
+var (
+	transactionNamespacesMu sync.RWMutex
+	transactionNamespaces   = map[string]HashNormaliser{
+		"eip155": normaliseEIP155Hash,
+		"bip122": normaliseBIP122Hash,
+		"cosmos": normaliseCosmosHash,
+		"solana": normaliseSolanaSignature,
+		"tron":   normaliseTronHash,
+	}
+)
+
+// RegisterTransactionNamespace registers a normaliser for the transaction hashes
+// of a chain namespace, replacing any normaliser already registered for it.
+// Hashes in namespaces without a registered normaliser are only checked against
+// the generic hash pattern and are kept as given.
+func RegisterTransactionNamespace(namespace string, normaliser HashNormaliser) {
+	transactionNamespacesMu.Lock()
+	defer transactionNamespacesMu.Unlock()
+
+	transactionNamespaces[namespace] = normaliser
+}
+
+func transactionNamespaceNormaliser(namespace string) (HashNormaliser, bool) {
+	transactionNamespacesMu.RLock()
+	defer transactionNamespacesMu.RUnlock()
+
+	normaliser, ok := transactionNamespaces[namespace]
+	return normaliser, ok
+}
+
+// AddressDeriver derives the account address of a public key on a chain
+// belonging to a single CAIP-2 namespace
+type AddressDeriver func(chainId ChainId, publicKey []byte) (string, error)
+
+var (
+	addressDeriversMu sync.RWMutex
+	addressDerivers   = map[string]AddressDeriver{
+		"eip155": deriveEIP155Address,
+		"bip122": deriveBIP122Address,
+		"cosmos": deriveCosmosAddress,
+		"solana": deriveSolanaAddress,
+		"tron":   deriveTronAddress,
+	}
+)
+
+// RegisterAddressDeriver registers a deriver for the account addresses of a
+// chain namespace, replacing any deriver already registered for it
+func RegisterAddressDeriver(namespace string, deriver AddressDeriver) {
+	addressDeriversMu.Lock()
+	defer addressDeriversMu.Unlock()
+
+	addressDerivers[namespace] = deriver
+}
+
+func namespaceAddressDeriver(namespace string) (AddressDeriver, bool) {
+	addressDeriversMu.RLock()
+	defer addressDeriversMu.RUnlock()
+
+	deriver, ok := addressDerivers[namespace]
+	return deriver, ok
+}
+
+// MemoNormaliser validates a deposit memo on a chain belonging to a single
+// CAIP-2 namespace and returns the canonical form of the memo
+type MemoNormaliser func(chainId ChainId, memo Memo) (Memo, error)
+
+var (
+	memoNamespacesMu sync.RWMutex
+	memoNamespaces   = map[string]MemoNormaliser{
+		"xrpl":    normaliseXRPLMemo,
+		"stellar": normaliseStellarMemo,
+		"cosmos":  normaliseCosmosMemo,
+		"ton":     normaliseTONMemo,
+	}
+)
+
+// RegisterMemoNamespace registers a normaliser for the deposit memos of a chain
+// namespace, replacing any normaliser already registered for it. Deposit
+// addresses in namespaces without a registered normaliser cannot have a memo.
+func RegisterMemoNamespace(namespace string, normaliser MemoNormaliser) {
+	memoNamespacesMu.Lock()
+	defer memoNamespacesMu.Unlock()
+
+	memoNamespaces[namespace] = normaliser
+}
+
+// UnregisterMemoNamespace removes the memo normaliser of a chain namespace
+func UnregisterMemoNamespace(namespace string) {
+	memoNamespacesMu.Lock()
+	defer memoNamespacesMu.Unlock()
+
+	delete(memoNamespaces, namespace)
+}
+
+func memoNamespaceNormaliser(namespace string) (MemoNormaliser, bool) {
+	memoNamespacesMu.RLock()
+	defer memoNamespacesMu.RUnlock()
+
+	normaliser, ok := memoNamespaces[namespace]
+	return normaliser, ok
+}
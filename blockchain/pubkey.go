@@ -0,0 +1,38 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// NewAccountIdFromPublicKey derives the account id of a public key on a chain.
+// Chains using secp256k1 accept compressed or uncompressed keys and solana
+// accepts 32 byte ed25519 keys. Bitcoin accounts are derived as P2WPKH, use
+// NewBitcoinAccountIdFromPublicKey for other script types.
+func NewAccountIdFromPublicKey(chainId ChainId, publicKey []byte) (AccountId, error) {
+	if err := chainId.validate(); err != nil {
+		return AccountId{}, err
+	}
+
+	deriver, ok := namespaceAddressDeriver(chainId.Namespace)
+	if !ok {
+		return AccountId{}, fmt.Errorf("deriving addresses is not supported in namespace %s", chainId.Namespace)
+	}
+
+	address, err := deriver(chainId, publicKey)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	return NewAccountId(chainId, address)
+}
+
+func parseSecp256k1PublicKey(publicKey []byte) (*secp256k1.PublicKey, error) {
+	key, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secp256k1 public key: %w", err)
+	}
+
+	return key, nil
+}
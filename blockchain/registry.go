@@ -0,0 +1,255 @@
+package blockchain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChainInfo holds metadata about a chain
+type ChainInfo struct {
+	ChainId ChainId
+	Name    string
+	// Aliases are human friendly names the chain can be parsed from
+	Aliases        []string
+	NativeAsset    AssetId
+	NativeSymbol   string
+	NativeDecimals int32
+	Testnet        bool
+	// BlockTime is the average time between blocks
+	BlockTime time.Duration
+	// FinalityDepth is the number of confirmations after which a block is
+	// considered final
+	FinalityDepth uint64
+	// Explorer holds the block explorer URL templates of the chain
+	Explorer ExplorerTemplates
+}
+
+type chainInfoJSON struct {
+	ChainId        ChainId           `json:"chainId"`
+	Name           string            `json:"name"`
+	Aliases        []string          `json:"aliases,omitempty"`
+	NativeAsset    AssetId           `json:"nativeAsset"`
+	NativeSymbol   string            `json:"nativeSymbol,omitempty"`
+	NativeDecimals int32             `json:"nativeDecimals"`
+	Testnet        bool              `json:"testnet"`
+	BlockTime      string            `json:"blockTime"`
+	FinalityDepth  uint64            `json:"finalityDepth"`
+	Explorer       ExplorerTemplates `json:"explorer"`
+}
+
+//go:embed chains.json
+var embeddedChains []byte
+
+var (
+	chainsMu     sync.RWMutex
+	chains       = map[ChainId]ChainInfo{}
+	chainAliases = map[string]ChainId{}
+)
+
+func init() {
+	if err := LoadChains(bytes.NewReader(embeddedChains)); err != nil {
+		panic(fmt.Errorf("loading embedded chains: %w", err))
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ChainInfo) UnmarshalJSON(data []byte) error {
+	var info chainInfoJSON
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+
+	var blockTime time.Duration
+	if info.BlockTime != "" {
+		var err error
+		if blockTime, err = time.ParseDuration(info.BlockTime); err != nil {
+			return fmt.Errorf("parsing block time of chain %s: %w", info.ChainId, err)
+		}
+	}
+
+	*c = ChainInfo{
+		ChainId:        info.ChainId,
+		Name:           info.Name,
+		Aliases:        info.Aliases,
+		NativeAsset:    info.NativeAsset,
+		NativeSymbol:   info.NativeSymbol,
+		NativeDecimals: info.NativeDecimals,
+		Testnet:        info.Testnet,
+		BlockTime:      blockTime,
+		FinalityDepth:  info.FinalityDepth,
+		Explorer:       info.Explorer,
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c ChainInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chainInfoJSON{
+		ChainId:        c.ChainId,
+		Name:           c.Name,
+		Aliases:        c.Aliases,
+		NativeAsset:    c.NativeAsset,
+		NativeSymbol:   c.NativeSymbol,
+		NativeDecimals: c.NativeDecimals,
+		Testnet:        c.Testnet,
+		BlockTime:      c.BlockTime.String(),
+		FinalityDepth:  c.FinalityDepth,
+		Explorer:       c.Explorer,
+	})
+}
+
+// RegisterChain adds a chain to the registry, replacing any chain already
+// registered with the same chain id
+func RegisterChain(info ChainInfo) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	registerChain(info)
+}
+
+// UnregisterChain removes a chain and its aliases from the registry
+func UnregisterChain(chainId ChainId) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	unregisterChain(chainId)
+}
+
+// registerChain adds a chain and its aliases to the registry, removing the
+// aliases of any chain it replaces. The caller must hold chainsMu.
+func registerChain(info ChainInfo) {
+	unregisterChain(info.ChainId)
+
+	chains[info.ChainId] = info
+	for _, alias := range info.Aliases {
+		chainAliases[normaliseAlias(alias)] = info.ChainId
+	}
+}
+
+// unregisterChain removes a chain and the aliases that still refer to it. The
+// caller must hold chainsMu.
+func unregisterChain(chainId ChainId) {
+	previous, ok := chains[chainId]
+	if !ok {
+		return
+	}
+
+	for _, alias := range previous.Aliases {
+		if chainAliases[normaliseAlias(alias)] == chainId {
+			delete(chainAliases, normaliseAlias(alias))
+		}
+	}
+
+	delete(chains, chainId)
+}
+
+// LoadChains reads a JSON array of chains and adds them to the registry,
+// replacing any chains already registered with the same chain ids
+func LoadChains(r io.Reader) error {
+	var infos []ChainInfo
+	if err := json.NewDecoder(r).Decode(&infos); err != nil {
+		return fmt.Errorf("decoding chains: %w", err)
+	}
+
+	for _, info := range infos {
+		if info.ChainId == (ChainId{}) {
+			return fmt.Errorf("chain %q has no chain id", info.Name)
+		}
+
+		if info.NativeAsset != (AssetId{}) && info.NativeAsset.ChainId != info.ChainId {
+			return fmt.Errorf("native asset %s is not on chain %s", info.NativeAsset, info.ChainId)
+		}
+	}
+
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	for _, info := range infos {
+		registerChain(info)
+	}
+
+	return nil
+}
+
+// LookupChain returns the registered metadata for a chain
+func LookupChain(chainId ChainId) (ChainInfo, bool) {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+
+	info, ok := chains[chainId]
+	return info, ok
+}
+
+// Chains returns all registered chains ordered by chain id
+func Chains() []ChainInfo {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+
+	infos := make([]ChainInfo, 0, len(chains))
+	for _, info := range chains {
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ChainId.String() < infos[j].ChainId.String()
+	})
+
+	return infos
+}
+
+// Info returns the registered metadata for the chain
+func (c ChainId) Info() (ChainInfo, bool) {
+	return LookupChain(c)
+}
+
+// RegisterChainAlias registers an additional alias a chain can be parsed from,
+// replacing any chain the alias already refers to
+func RegisterChainAlias(alias string, chainId ChainId) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	chainAliases[normaliseAlias(alias)] = chainId
+}
+
+// UnregisterChainAlias removes an alias from the registry
+func UnregisterChainAlias(alias string) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	delete(chainAliases, normaliseAlias(alias))
+}
+
+// ParseChainAlias parses a chain id from a registered alias such as ethereum or
+// bitcoin-testnet, falling back to the CAIP-2 string form, namespace:reference
+func ParseChainAlias(s string) (ChainId, error) {
+	chainsMu.RLock()
+	chainId, ok := chainAliases[normaliseAlias(s)]
+	chainsMu.RUnlock()
+
+	if ok {
+		return chainId, nil
+	}
+
+	return ParseChainId(s)
+}
+
+// DisplayName returns the registered name of the chain, or the string form of
+// the chain id if it is not registered
+func (c ChainId) DisplayName() string {
+	if info, ok := LookupChain(c); ok && info.Name != "" {
+		return info.Name
+	}
+
+	return c.String()
+}
+
+func normaliseAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
@@ -0,0 +1,177 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/offblocks/offblocks-common/errors"
+)
+
+// CoinType holds the SLIP-44 registration of a coin type
+type CoinType struct {
+	CoinType uint32 `json:"coinType"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Decimals int32  `json:"decimals"`
+}
+
+//go:embed slip44.json
+var embeddedCoinTypes []byte
+
+var (
+	slip44ReferenceRegex = regexp.MustCompile("^(0|[1-9][0-9]{0,9})$")
+
+	coinTypesMu sync.RWMutex
+	coinTypes   = map[uint32]CoinType{}
+
+	// nativeCoinTypes maps chain namespaces whose chains all share the same
+	// native asset to the reference of their mainnet and its coin type, for
+	// chains that are not in the registry. Every other chain in the namespace
+	// is a testnet. Namespaces such as eip155 and cosmos, whose chains have
+	// different native assets, are not included.
+	nativeCoinTypes = map[string]nativeCoinType{
+		"solana": {"5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", 501},
+		"tron":   {"0x2b6653dc", 195},
+	}
+)
+
+type nativeCoinType struct {
+	mainnet  string
+	coinType uint32
+}
+
+// testnetCoinType is the SLIP-44 coin type shared by the native assets of all
+// testnets
+const testnetCoinType = 1
+
+func init() {
+	var table []CoinType
+	if err := json.NewDecoder(bytes.NewReader(embeddedCoinTypes)).Decode(&table); err != nil {
+		panic(fmt.Errorf("loading embedded coin types: %w", err))
+	}
+
+	for _, coinType := range table {
+		coinTypes[coinType.CoinType] = coinType
+	}
+}
+
+// RegisterCoinType adds a coin type to the registry, replacing any coin type
+// already registered with the same number
+func RegisterCoinType(coinType CoinType) {
+	coinTypesMu.Lock()
+	defer coinTypesMu.Unlock()
+
+	coinTypes[coinType.CoinType] = coinType
+}
+
+// UnregisterCoinType removes a coin type from the registry
+func UnregisterCoinType(coinType uint32) {
+	coinTypesMu.Lock()
+	defer coinTypesMu.Unlock()
+
+	delete(coinTypes, coinType)
+}
+
+// LookupCoinType returns the registered metadata for a SLIP-44 coin type
+func LookupCoinType(coinType uint32) (CoinType, bool) {
+	coinTypesMu.RLock()
+	defer coinTypesMu.RUnlock()
+
+	c, ok := coinTypes[coinType]
+	return c, ok
+}
+
+// normaliseSLIP44Reference validates that a reference is a SLIP-44 coin type
+func normaliseSLIP44Reference(_ ChainId, reference string) (string, error) {
+	if !slip44ReferenceRegex.MatchString(reference) {
+		return "", fmt.Errorf("slip44 reference must be a coin type integer: %s", reference)
+	}
+
+	if _, err := strconv.ParseUint(reference, 10, 31); err != nil {
+		return "", fmt.Errorf("slip44 coin type out of range: %s", reference)
+	}
+
+	return reference, nil
+}
+
+// NewSLIP44Asset creates the asset id of a chain's native asset identified by
+// its SLIP-44 coin type
+func NewSLIP44Asset(chainId ChainId, coinType uint32) (AssetId, error) {
+	return NewAssetId(chainId, "slip44", strconv.FormatUint(uint64(coinType), 10))
+}
+
+// CoinType returns the registered metadata for the coin type of a slip44 asset
+func (a AssetId) CoinType() (CoinType, error) {
+	if a.Namespace != "slip44" {
+		return CoinType{}, fmt.Errorf("asset %s is not a slip44 asset", a)
+	}
+
+	number, err := strconv.ParseUint(a.Reference, 10, 31)
+	if err != nil {
+		return CoinType{}, fmt.Errorf("slip44 coin type out of range: %s", a.Reference)
+	}
+
+	coinType, ok := LookupCoinType(uint32(number))
+	if !ok {
+		return CoinType{}, fmt.Errorf("unknown slip44 coin type %s: %w", a.Reference, errors.ErrNotFound)
+	}
+
+	return coinType, nil
+}
+
+// NativeAsset returns the slip44 asset id of the native asset of a chain. The
+// native asset of a registered chain is used if set, otherwise the coin type is
+// derived from the network for bip122 chains or from the chain namespace if all
+// of its chains share a native asset. Testnet native assets use coin type 1,
+// including those of unregistered chains in such a namespace other than its
+// mainnet.
+func NativeAsset(chainId ChainId) (AssetId, error) {
+	if info, ok := LookupChain(chainId); ok && info.NativeAsset != (AssetId{}) {
+		return info.NativeAsset, nil
+	}
+
+	if chainId.Namespace == "bip122" {
+		if network, ok := bitcoinNetworks[chainId.Reference]; ok {
+			return NewSLIP44Asset(chainId, network.coinType)
+		}
+	}
+
+	if native, ok := nativeCoinTypes[chainId.Namespace]; ok {
+		if chainId.Reference != native.mainnet {
+			return NewSLIP44Asset(chainId, testnetCoinType)
+		}
+
+		return NewSLIP44Asset(chainId, native.coinType)
+	}
+
+	return AssetId{}, fmt.Errorf("no native asset known for chain %s: %w", chainId, errors.ErrNotFound)
+}
+
+// SLIP44AssetMetadataProvider is an asset metadata provider for slip44 native
+// assets, using the metadata of registered chains in preference to the coin
+// type table so that chains sharing a coin type are displayed correctly
+type SLIP44AssetMetadataProvider struct{}
+
+func (SLIP44AssetMetadataProvider) AssetMetadata(_ context.Context, asset AssetId) (AssetMetadata, error) {
+	coinType, err := asset.CoinType()
+	if err != nil {
+		return AssetMetadata{}, fmt.Errorf("no metadata for asset %s: %w", asset, errors.ErrNotFound)
+	}
+
+	metadata := AssetMetadata{Symbol: coinType.Symbol, Name: coinType.Name, Decimals: coinType.Decimals}
+	if info, ok := LookupChain(asset.ChainId); ok && info.NativeAsset == asset {
+		metadata.Decimals = info.NativeDecimals
+		if info.NativeSymbol != "" && info.NativeSymbol != coinType.Symbol {
+			metadata.Symbol = info.NativeSymbol
+			metadata.Name = info.Name
+		}
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/offblocks/offblocks-common/internal/base58"
+)
+
+// normaliseSolanaAddress validates that an address is a Base58 encoded 32 byte
+// ed25519 public key
+func normaliseSolanaAddress(_ ChainId, address string) (string, error) {
+	if _, err := decodeSolanaKey(address); err != nil {
+		return "", err
+	}
+
+	return address, nil
+}
+
+func decodeSolanaKey(key string) ([]byte, error) {
+	b, err := base58.Decode(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid solana address %s: %w", key, err)
+	}
+
+	if len(b) != 32 {
+		return nil, fmt.Errorf("solana address must be 32 bytes: %s", key)
+	}
+
+	return b, nil
+}
+
+// deriveSolanaAddress derives the address of an ed25519 public key, which is
+// the Base58 encoding of the key
+func deriveSolanaAddress(_ ChainId, publicKey []byte) (string, error) {
+	if len(publicKey) != 32 {
+		return "", fmt.Errorf("solana public key must be 32 bytes, got %d", len(publicKey))
+	}
+
+	return base58.Encode(publicKey), nil
+}
+
+// normaliseSPLReference validates that an asset reference is an SPL token mint
+func normaliseSPLReference(_ ChainId, reference string) (string, error) {
+	if _, err := decodeSolanaKey(reference); err != nil {
+		return "", err
+	}
+
+	return reference, nil
+}
+
+// NewSPLAsset creates the asset id of an SPL token mint
+func NewSPLAsset(chainId ChainId, mint string) (AssetId, error) {
+	return NewAssetId(chainId, "spl", mint)
+}
+
+// normaliseSolanaBlockhash validates that a block hash is a Base58 encoded 32
+// byte hash
+func normaliseSolanaBlockhash(_ ChainId, hash string) (string, error) {
+	b, err := base58.Decode(hash)
+	if err != nil {
+		return "", fmt.Errorf("invalid solana blockhash %s: %w", hash, err)
+	}
+
+	if len(b) != 32 {
+		return "", fmt.Errorf("solana blockhash must be 32 bytes: %s", hash)
+	}
+
+	return hash, nil
+}
+
+// normaliseSolanaSignature validates that a transaction hash is a Base58 encoded
+// 64 byte ed25519 signature
+func normaliseSolanaSignature(_ ChainId, hash string) (string, error) {
+	b, err := base58.Decode(hash)
+	if err != nil {
+		return "", fmt.Errorf("invalid solana transaction signature %s: %w", hash, err)
+	}
+
+	if len(b) != 64 {
+		return "", fmt.Errorf("solana transaction signature must be 64 bytes: %s", hash)
+	}
+
+	return hash, nil
+}
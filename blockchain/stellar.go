@@ -0,0 +1,20 @@
+package blockchain
+
+import "fmt"
+
+// stellarMaxTextMemo is the maximum length in bytes of a MEMO_TEXT
+const stellarMaxTextMemo = 28
+
+// normaliseStellarMemo validates a Stellar MEMO_TEXT, MEMO_ID or MEMO_HASH
+func normaliseStellarMemo(_ ChainId, memo Memo) (Memo, error) {
+	switch memo.Type {
+	case MemoText:
+		return normaliseMemoText("stellar", memo, stellarMaxTextMemo)
+	case MemoId:
+		return normaliseMemoId("stellar", memo, 64)
+	case MemoHash:
+		return normaliseMemoHash("stellar", memo)
+	default:
+		return Memo{}, fmt.Errorf("unsupported stellar memo type: %s", memo.Type)
+	}
+}
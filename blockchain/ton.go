@@ -0,0 +1,16 @@
+package blockchain
+
+import "fmt"
+
+// tonMaxComment is the maximum length in bytes of a text comment that fits in a
+// single cell after its 32 bit zero opcode
+const tonMaxComment = 123
+
+// normaliseTONMemo validates a TON text comment
+func normaliseTONMemo(_ ChainId, memo Memo) (Memo, error) {
+	if memo.Type != MemoText {
+		return Memo{}, fmt.Errorf("ton memos must be text comments of type %s, got %s", MemoText, memo.Type)
+	}
+
+	return normaliseMemoText("ton", memo, tonMaxComment)
+}
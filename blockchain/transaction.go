@@ -13,13 +13,19 @@ import (
 	"github.com/offblocks/offblocks-common/util"
 )
 
+// TransactionId is a transaction hash on a chain. Transaction ids should be
+// created with NewTransactionId or parsed so that their hash is in the
+// canonical form for the chain namespace; struct literals are validated and
+// canonicalised when written with Value.
 type TransactionId struct {
 	ChainId ChainId
 	Hash    string
 }
 
 var (
-	hashRegex = regexp.MustCompile("[a-zA-Z0-9]{1,128}")
+	hashRegex = regexp.MustCompile("^[a-zA-Z0-9]{1,128}$")
+
+	legacyHashRegex = regexp.MustCompile("[a-zA-Z0-9]{1,128}")
 )
 
 func NewTransactionId(ChainId ChainId, hash string) (TransactionId, error) {
@@ -28,7 +34,7 @@ func NewTransactionId(ChainId ChainId, hash string) (TransactionId, error) {
 		return TransactionId{}, err
 	}
 
-	return tID, nil
+	return tID.normalise()
 }
 
 func (t TransactionId) validate() error {
@@ -37,12 +43,54 @@ func (t TransactionId) validate() error {
 	}
 
 	if ok := hashRegex.Match([]byte(t.Hash)); !ok {
-		return errors.New("namespace does not match spec")
+		return errors.New("hash does not match spec")
+	}
+
+	return nil
+}
+
+// normalise applies the hash normaliser registered for the chain namespace,
+// returning the transaction id with its hash in canonical form
+func (t TransactionId) normalise() (TransactionId, error) {
+	normaliser, ok := transactionNamespaceNormaliser(t.ChainId.Namespace)
+	if !ok {
+		return t, nil
+	}
+
+	hash, err := normaliser(t.ChainId, t.Hash)
+	if err != nil {
+		return TransactionId{}, err
+	}
+
+	return TransactionId{t.ChainId, hash}, nil
+}
+
+// validateLegacy validates the transaction id against the unanchored patterns
+// used before strict validation was introduced
+func (t TransactionId) validateLegacy() error {
+	if err := t.ChainId.validateLegacy(); err != nil {
+		return err
+	}
+
+	if ok := legacyHashRegex.Match([]byte(t.Hash)); !ok {
+		return errors.New("hash does not match spec")
 	}
 
 	return nil
 }
 
+// Canonical returns the transaction id with its hash in the canonical form for
+// the chain namespace, or the transaction id unchanged if it cannot be
+// normalised
+func (t TransactionId) Canonical() TransactionId {
+	canonical, err := t.normalise()
+	if err != nil {
+		return t
+	}
+
+	return canonical
+}
+
 // String returns the string form of transaction id, chain_namespace:chain_reference:hash
 func (t TransactionId) String() string {
 	return t.ChainId.String() + ":" + t.Hash
@@ -50,19 +98,39 @@ func (t TransactionId) String() string {
 
 // Parse parses a string into a transaction id from the string form, chain_namespace:chain_reference:hash
 func (t *TransactionId) Parse(s string) error {
+	return t.parse(s, true)
+}
+
+func (t *TransactionId) parse(s string, strict bool) error {
 	split := strings.SplitN(s, ":", 3)
 	if len(split) != 3 {
 		return fmt.Errorf("invalid transaction id: %s", s)
 	}
 
-	*t = TransactionId{ChainId{split[0], split[1]}, split[2]}
-	if err := t.validate(); err != nil {
+	tID, err := TransactionId{ChainId{split[0], split[1]}, split[2]}.canonical(strict)
+	if err != nil {
 		return err
 	}
 
+	*t = tID
 	return nil
 }
 
+// canonical validates the transaction id, against the legacy patterns unless
+// strict, and returns it with its hash in canonical form
+func (t TransactionId) canonical(strict bool) (TransactionId, error) {
+	validate := t.validate
+	if !strict {
+		validate = t.validateLegacy
+	}
+
+	if err := validate(); err != nil {
+		return TransactionId{}, err
+	}
+
+	return t.normalise()
+}
+
 // MustParse parses a string into a transaction id from the string form, chain_namespace:chain_reference:hash
 // and panics if there is an error
 func (c *TransactionId) MustParse(s string) {
@@ -147,7 +215,12 @@ func (t TransactionId) MarshalProto() (string, error) {
 }
 
 func (t TransactionId) Value() (driver.Value, error) {
-	return t.String(), nil
+	canonical, err := t.canonical(!LenientScan())
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction id %s: %w", t, err)
+	}
+
+	return canonical.String(), nil
 }
 
 func (t *TransactionId) Scan(src interface{}) error {
@@ -160,7 +233,7 @@ func (t *TransactionId) Scan(src interface{}) error {
 		return nil
 	}
 
-	if err := t.Parse(i.String); err != nil {
+	if err := t.parse(i.String, !LenientScan()); err != nil {
 		return err
 	}
 
@@ -173,7 +246,7 @@ func (t TransactionId) MarshalGQL(w io.Writer) {
 
 func (t *TransactionId) UnmarshalGQL(v interface{}) error {
 	if id, ok := v.(string); ok {
-		if err := t.Parse(id); err != nil {
+		if err := unmarshalGQLId(id, t.Parse); err != nil {
 			return fmt.Errorf("unmarshalling transaction id: %w", err)
 		}
 	}
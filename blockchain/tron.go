@@ -0,0 +1,97 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+const tronAddressPrefix = 0x41
+
+// normaliseTronAddress validates that an address is Base58Check encoded with
+// the 0x41 tron prefix
+func normaliseTronAddress(_ ChainId, address string) (string, error) {
+	if _, err := decodeTronAddress(address); err != nil {
+		return "", err
+	}
+
+	return address, nil
+}
+
+func decodeTronAddress(address string) ([]byte, error) {
+	payload, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tron address %s: %w", address, err)
+	}
+
+	if len(payload) != 21 || payload[0] != tronAddressPrefix {
+		return nil, fmt.Errorf("tron address must be 21 bytes with 0x41 prefix: %s", address)
+	}
+
+	return payload, nil
+}
+
+// deriveTronAddress derives the address of a secp256k1 public key, which is the
+// eip155 address of the key with the 0x41 tron prefix
+func deriveTronAddress(_ ChainId, publicKey []byte) (string, error) {
+	key, err := parseSecp256k1PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	payload := append([]byte{tronAddressPrefix}, hashes.Keccak256(key.SerializeUncompressed()[1:])[12:]...)
+	return base58.CheckEncode(payload), nil
+}
+
+// TronHexToBase58 converts a tron address from its hex form, either 41 prefixed
+// or a 0x prefixed 20 byte EVM style address, to its Base58Check form
+func TronHexToBase58(address string) (string, error) {
+	var payload []byte
+	switch {
+	case strings.HasPrefix(address, "0x") && len(address) == 42:
+		b, err := hex.DecodeString(address[2:])
+		if err != nil {
+			return "", fmt.Errorf("invalid tron hex address %s: %w", address, err)
+		}
+		payload = append([]byte{tronAddressPrefix}, b...)
+	case len(address) == 42:
+		b, err := hex.DecodeString(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid tron hex address %s: %w", address, err)
+		}
+		payload = b
+	default:
+		return "", fmt.Errorf("invalid tron hex address length: %s", address)
+	}
+
+	if payload[0] != tronAddressPrefix {
+		return "", fmt.Errorf("tron hex address must have 41 prefix: %s", address)
+	}
+
+	return base58.CheckEncode(payload), nil
+}
+
+// TronBase58ToHex converts a tron address from its Base58Check form to its 41
+// prefixed hex form
+func TronBase58ToHex(address string) (string, error) {
+	payload, err := decodeTronAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(payload), nil
+}
+
+// normaliseTronHash validates a 32 byte hex transaction id, with or without a 0x
+// prefix, and returns the unprefixed lowercase form
+func normaliseTronHash(_ ChainId, hash string) (string, error) {
+	hash = strings.TrimPrefix(hash, "0x")
+	if !hex32Regex.MatchString(hash) {
+		return "", fmt.Errorf("tron transaction id must be 32 byte hex: %s", hash)
+	}
+
+	return strings.ToLower(hash), nil
+}
@@ -0,0 +1,193 @@
+package blockchain
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/offblocks/offblocks-common/internal/hashes"
+)
+
+// ExtendedPublicKey is a BIP-32 extended public key, such as an xpub, from
+// which non-hardened child public keys can be derived
+type ExtendedPublicKey struct {
+	// Version is the serialization version, which identifies the network and
+	// script type, e.g. 0x0488b21e for xpub
+	Version           uint32
+	Depth             uint8
+	ParentFingerprint uint32
+	ChildNumber       uint32
+	ChainCode         []byte
+	// PublicKey is the compressed secp256k1 public key
+	PublicKey []byte
+}
+
+const extendedKeyLength = 78
+
+// extendedKeyVersion is the network and script type identified by the version
+// of an extended public key
+type extendedKeyVersion struct {
+	testnet    bool
+	scriptType BitcoinScriptType
+}
+
+// extendedKeyVersions maps the SLIP-132 versions of extended public keys to the
+// network and script type of the addresses derived from them
+var extendedKeyVersions = map[uint32]extendedKeyVersion{
+	// xpub
+	0x0488b21e: {false, BitcoinP2PKH},
+	// ypub
+	0x049d7cb2: {false, BitcoinP2SH},
+	// zpub
+	0x04b24746: {false, BitcoinP2WPKH},
+	// tpub
+	0x043587cf: {true, BitcoinP2PKH},
+	// upub
+	0x044a5262: {true, BitcoinP2SH},
+	// vpub
+	0x045f1cf6: {true, BitcoinP2WPKH},
+}
+
+// ParseExtendedPublicKey parses a Base58Check encoded extended public key
+func ParseExtendedPublicKey(s string) (ExtendedPublicKey, error) {
+	payload, err := base58.CheckDecode(s)
+	if err != nil {
+		return ExtendedPublicKey{}, fmt.Errorf("invalid extended public key: %w", err)
+	}
+
+	if len(payload) != extendedKeyLength {
+		return ExtendedPublicKey{}, fmt.Errorf("extended public key must be %d bytes, got %d", extendedKeyLength, len(payload))
+	}
+
+	key := ExtendedPublicKey{
+		Version:           binary.BigEndian.Uint32(payload[0:4]),
+		Depth:             payload[4],
+		ParentFingerprint: binary.BigEndian.Uint32(payload[5:9]),
+		ChildNumber:       binary.BigEndian.Uint32(payload[9:13]),
+		ChainCode:         payload[13:45],
+		PublicKey:         payload[45:78],
+	}
+
+	// Extended private keys hold a zero byte followed by the private key
+	if _, err := parseSecp256k1PublicKey(key.PublicKey); err != nil || len(key.PublicKey) != 33 {
+		return ExtendedPublicKey{}, fmt.Errorf("extended key does not hold a compressed public key")
+	}
+
+	if key.Depth == 0 && (key.ParentFingerprint != 0 || key.ChildNumber != 0) {
+		return ExtendedPublicKey{}, fmt.Errorf("master extended public key has a parent")
+	}
+
+	return key, nil
+}
+
+// String returns the Base58Check encoding of the extended public key
+func (k ExtendedPublicKey) String() string {
+	payload := make([]byte, 0, extendedKeyLength)
+	payload = binary.BigEndian.AppendUint32(payload, k.Version)
+	payload = append(payload, k.Depth)
+	payload = binary.BigEndian.AppendUint32(payload, k.ParentFingerprint)
+	payload = binary.BigEndian.AppendUint32(payload, k.ChildNumber)
+	payload = append(payload, k.ChainCode...)
+	payload = append(payload, k.PublicKey...)
+
+	return base58.CheckEncode(payload)
+}
+
+// Child derives the non-hardened child public key at an index
+func (k ExtendedPublicKey) Child(index uint32) (ExtendedPublicKey, error) {
+	if index >= HardenedKeyStart {
+		return ExtendedPublicKey{}, fmt.Errorf("cannot derive hardened child %d from a public key", index-HardenedKeyStart)
+	}
+
+	if k.Depth == 255 {
+		return ExtendedPublicKey{}, fmt.Errorf("extended public key is at the maximum depth")
+	}
+
+	parent, err := parseSecp256k1PublicKey(k.PublicKey)
+	if err != nil {
+		return ExtendedPublicKey{}, err
+	}
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(parent.SerializeCompressed())
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	i := mac.Sum(nil)
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(i[:32]); overflow {
+		return ExtendedPublicKey{}, fmt.Errorf("child %d is invalid, use the next index", index)
+	}
+
+	var p, t, q secp256k1.JacobianPoint
+	parent.AsJacobian(&p)
+	secp256k1.ScalarBaseMultNonConst(&tweak, &t)
+	secp256k1.AddNonConst(&p, &t, &q)
+	if (q.X.IsZero() && q.Y.IsZero()) || q.Z.IsZero() {
+		return ExtendedPublicKey{}, fmt.Errorf("child %d is invalid, use the next index", index)
+	}
+	q.ToAffine()
+
+	return ExtendedPublicKey{
+		Version:           k.Version,
+		Depth:             k.Depth + 1,
+		ParentFingerprint: binary.BigEndian.Uint32(hashes.Hash160(k.PublicKey)[:4]),
+		ChildNumber:       index,
+		ChainCode:         i[32:],
+		PublicKey:         secp256k1.NewPublicKey(&q.X, &q.Y).SerializeCompressed(),
+	}, nil
+}
+
+// Derive derives the public key at a path relative to the extended public key,
+// which must only contain non-hardened components. For example, deriving m/0/5
+// from the xpub of m/44'/60'/0' gives the key of m/44'/60'/0'/0/5.
+func (k ExtendedPublicKey) Derive(path DerivationPath) (ExtendedPublicKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return ExtendedPublicKey{}, err
+		}
+	}
+
+	return key, nil
+}
+
+// DeriveAccountId derives the account id on a chain of the public key at a path
+// relative to the extended public key. On bip122 chains the version of the key
+// must match the network of the chain and selects the script type: xpub and
+// tpub keys derive p2pkh addresses, ypub and upub keys p2sh wrapped p2wpkh
+// addresses and zpub and vpub keys p2wpkh addresses. Other chains only accept
+// xpub and tpub keys.
+func (k ExtendedPublicKey) DeriveAccountId(chainId ChainId, path DerivationPath) (AccountId, error) {
+	version, ok := extendedKeyVersions[k.Version]
+	if !ok {
+		return AccountId{}, fmt.Errorf("unsupported extended public key version %#08x", k.Version)
+	}
+
+	if chainId.Namespace == "bip122" {
+		network, ok := bitcoinNetworks[chainId.Reference]
+		if !ok {
+			return AccountId{}, fmt.Errorf("unknown bip122 network: %s", chainId.Reference)
+		}
+
+		if version.testnet != network.testnet {
+			return AccountId{}, fmt.Errorf("extended public key version %#08x is not for the network of chain %s", k.Version, chainId)
+		}
+	} else if version.scriptType != BitcoinP2PKH {
+		return AccountId{}, fmt.Errorf("extended public key version %#08x is only supported on bip122 chains", k.Version)
+	}
+
+	key, err := k.Derive(path)
+	if err != nil {
+		return AccountId{}, err
+	}
+
+	if chainId.Namespace == "bip122" {
+		return NewBitcoinAccountIdFromPublicKey(chainId, key.PublicKey, version.scriptType)
+	}
+
+	return NewAccountIdFromPublicKey(chainId, key.PublicKey)
+}
@@ -0,0 +1,13 @@
+package blockchain
+
+import "fmt"
+
+// normaliseXRPLMemo validates an XRP Ledger destination tag, a 32 bit unsigned
+// integer
+func normaliseXRPLMemo(_ ChainId, memo Memo) (Memo, error) {
+	if memo.Type != MemoId {
+		return Memo{}, fmt.Errorf("xrpl memos must be destination tags of type %s, got %s", MemoId, memo.Type)
+	}
+
+	return normaliseMemoId("xrpl", memo, 32)
+}
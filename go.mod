@@ -4,11 +4,13 @@ go 1.21.4
 
 require (
 	buf.build/gen/go/offblocks/offblocks-proto/protocolbuffers/go v1.33.0-20240123133924-c266684a3dae.1
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0
 	github.com/google/uuid v1.6.0
 	github.com/shopspring/decimal v1.4.0
 	github.com/stretchr/testify v1.9.0
 	go.temporal.io/api v1.32.0
 	go.temporal.io/sdk v1.26.1
+	golang.org/x/crypto v0.22.0
 	google.golang.org/grpc v1.63.2
 	google.golang.org/protobuf v1.33.0
 )
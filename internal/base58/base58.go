@@ -0,0 +1,107 @@
+// Package base58 implements the Base58 and Base58Check encodings used by
+// Bitcoin and derived chains
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	alphabetRev [128]int8
+	radix       = big.NewInt(58)
+)
+
+func init() {
+	for i := range alphabetRev {
+		alphabetRev[i] = -1
+	}
+	for i, c := range alphabet {
+		alphabetRev[c] = int8(i)
+	}
+}
+
+// Encode encodes bytes into a Base58 string
+func Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+// Decode decodes a Base58 string into bytes
+func Decode(s string) ([]byte, error) {
+	if len(s) == 0 {
+		return nil, errors.New("empty base58 string")
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 128 || alphabetRev[c] == -1 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(alphabetRev[c])))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// CheckEncode encodes a payload into a Base58Check string, appending the first
+// four bytes of its double SHA-256 as a checksum
+func CheckEncode(payload []byte) string {
+	return Encode(append(payload[:len(payload):len(payload)], checksum(payload)...))
+}
+
+// CheckDecode decodes a Base58Check string, verifying and removing its checksum
+func CheckDecode(s string) ([]byte, error) {
+	b, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 5 {
+		return nil, errors.New("base58check string too short")
+	}
+
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	if !bytes.Equal(checksum(payload), sum) {
+		return nil, errors.New("base58check string has invalid checksum")
+	}
+
+	return payload, nil
+}
+
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,174 @@
+// Package bech32 implements the Bech32 and Bech32m encodings defined in BIP-173
+// and BIP-350
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Variant identifies the checksum constant used by an encoded string
+type Variant int
+
+const (
+	Bech32 Variant = iota + 1
+	Bech32m
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// MaxLength is the maximum length of an encoded string as defined in BIP-173
+const MaxLength = 90
+
+var charsetRev [128]int8
+
+func init() {
+	for i := range charsetRev {
+		charsetRev[i] = -1
+	}
+	for i, c := range charset {
+		charsetRev[c] = int8(i)
+	}
+}
+
+func polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func checksumConst(variant Variant) uint32 {
+	if variant == Bech32m {
+		return bech32mConst
+	}
+	return bech32Const
+}
+
+// Encode encodes a human readable part and 5-bit data values into a string
+// using the checksum of the given variant
+func Encode(hrp string, data []byte, variant Variant) (string, error) {
+	hrp = strings.ToLower(hrp)
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, make([]byte, 6)...)
+	mod := polymod(values) ^ checksumConst(variant)
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(data) + 6)
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		if d > 31 {
+			return "", fmt.Errorf("invalid data value %d", d)
+		}
+		sb.WriteByte(charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(charset[(mod>>uint(5*(5-i)))&31])
+	}
+
+	return sb.String(), nil
+}
+
+// Decode decodes a string no longer than limit characters into its human
+// readable part and 5-bit data values, excluding the checksum. A limit of zero
+// or less disables the length check.
+func Decode(s string, limit int) (string, []byte, Variant, error) {
+	if limit > 0 && len(s) > limit {
+		return "", nil, 0, fmt.Errorf("bech32 string exceeds %d characters", limit)
+	}
+
+	lower := strings.ToLower(s)
+	if s != lower && s != strings.ToUpper(s) {
+		return "", nil, 0, errors.New("bech32 string has mixed case")
+	}
+
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 || sep+7 > len(lower) {
+		return "", nil, 0, errors.New("bech32 string has invalid separator position")
+	}
+
+	hrp := lower[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errors.New("bech32 human readable part has invalid character")
+		}
+	}
+
+	data := make([]byte, 0, len(lower)-sep-1)
+	for i := sep + 1; i < len(lower); i++ {
+		c := lower[i]
+		if c >= 128 || charsetRev[c] == -1 {
+			return "", nil, 0, fmt.Errorf("bech32 string has invalid character %q", c)
+		}
+		data = append(data, byte(charsetRev[c]))
+	}
+
+	var variant Variant
+	switch polymod(append(hrpExpand(hrp), data...)) {
+	case bech32Const:
+		variant = Bech32
+	case bech32mConst:
+		variant = Bech32m
+	default:
+		return "", nil, 0, errors.New("bech32 string has invalid checksum")
+	}
+
+	return hrp, data[:len(data)-6], variant, nil
+}
+
+// ConvertBits regroups data values of fromBits width into values of toBits
+// width, padding the final group with zero bits when pad is set
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, d := range data {
+		if uint32(d)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data value %d", d)
+		}
+		acc = acc<<fromBits | uint32(d)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.New("invalid padding")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,39 @@
+// Package hashes implements the hash functions shared by address derivation
+// and signature verification
+package hashes
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// Keccak256 returns the legacy Keccak-256 hash of data used by Ethereum
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// Hash160 returns the RIPEMD-160 hash of the SHA-256 hash of data
+func Hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+// TaggedHash returns the BIP-340 tagged hash of data
+func TaggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,300 @@
+// Package lightning decodes Lightning Network payment requests
+package lightning
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/bech32"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+)
+
+// Invoice is a decoded BOLT11 payment request
+type Invoice struct {
+	// ChainId is the bip122 chain of the invoice's currency prefix
+	ChainId blockchain.ChainId
+	// Payee is the hex encoded compressed public key of the payee node
+	Payee string
+	// Amount is the amount in BTC with millisatoshi precision, or nil if the
+	// payer chooses the amount
+	Amount    *types.Decimal
+	Timestamp types.Time
+	// Expiry is the time after which the invoice should no longer be paid
+	Expiry types.Time
+	// Description is the purpose of the payment, empty if the invoice commits
+	// to a description hash instead
+	Description     string
+	DescriptionHash []byte
+	// PaymentHash is the SHA-256 hash of the payment preimage as a transaction
+	// id on the invoice's chain
+	PaymentHash   blockchain.TransactionId
+	PaymentSecret []byte
+	// MinFinalCLTVExpiryDelta is the minimum number of blocks the final hop
+	// requires before the payment times out
+	MinFinalCLTVExpiryDelta uint64
+}
+
+const (
+	defaultExpiry = time.Hour
+	// maxExpiry bounds the expiry field so that it cannot overflow; longer
+	// expiries are clamped
+	maxExpiry                      = 100 * 365 * 24 * time.Hour
+	defaultMinFinalCLTVExpiryDelta = 18
+	// maxMinFinalCLTVExpiryDelta bounds the min_final_cltv_expiry field to the
+	// range of a lock time; longer deltas are clamped
+	maxMinFinalCLTVExpiryDelta = math.MaxUint32
+
+	timestampWords = 7
+	signatureWords = 104
+)
+
+// Tagged field types, which are the bech32 values of the field letters
+const (
+	fieldPaymentHash        = 1  // p
+	fieldPaymentSecret      = 16 // s
+	fieldDescription        = 13 // d
+	fieldPayee              = 19 // n
+	fieldDescriptionHash    = 23 // h
+	fieldExpiry             = 6  // x
+	fieldMinFinalCLTVExpiry = 24 // c
+)
+
+var (
+	// networks maps BOLT11 currency prefixes to bip122 chains
+	networks = map[string]blockchain.ChainId{
+		"bc":   {Namespace: "bip122", Reference: "000000000019d6689c085ae165831e93"},
+		"tb":   {Namespace: "bip122", Reference: "000000000933ea01ad0ee984209779ba"},
+		"tbs":  {Namespace: "bip122", Reference: "00000008819873e925422c1ff0f99f7c"},
+		"bcrt": {Namespace: "bip122", Reference: "0f9188f13cb7b2c71f2a335e3a4fc328"},
+	}
+
+	hrpRegex = regexp.MustCompile("^ln([a-z]+?)(?:([1-9][0-9]*)([munp]?))?$")
+
+	multiplierExponents = map[string]int32{
+		"":  0,
+		"m": -3,
+		"u": -6,
+		"n": -9,
+		"p": -12,
+	}
+)
+
+// Decode decodes a BOLT11 invoice and verifies its signature, rejecting
+// invoices that were not signed by the payee node
+func Decode(invoice string) (Invoice, error) {
+	if len(invoice) > 10 && strings.EqualFold(invoice[:10], "lightning:") {
+		invoice = invoice[10:]
+	}
+
+	hrp, words, variant, err := bech32.Decode(invoice, 0)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("invalid bolt11 invoice: %w", err)
+	}
+
+	if variant != bech32.Bech32 {
+		return Invoice{}, fmt.Errorf("invalid bolt11 invoice checksum variant")
+	}
+
+	inv, err := decodeHRP(hrp)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if len(words) < timestampWords+signatureWords {
+		return Invoice{}, fmt.Errorf("bolt11 invoice is too short")
+	}
+
+	payload, signature := words[:len(words)-signatureWords], words[len(words)-signatureWords:]
+	timestamp := time.Unix(int64(wordsToUint(payload[:timestampWords])), 0).UTC()
+	inv.Timestamp = types.Time{Time: timestamp}
+
+	expiry := defaultExpiry
+	inv.MinFinalCLTVExpiryDelta = defaultMinFinalCLTVExpiryDelta
+
+	var payee, paymentHash []byte
+	for i := timestampWords; i < len(payload); {
+		if i+3 > len(payload) {
+			return Invoice{}, fmt.Errorf("bolt11 tagged field is truncated")
+		}
+
+		tag, length := payload[i], int(payload[i+1])<<5|int(payload[i+2])
+		i += 3
+		if i+length > len(payload) {
+			return Invoice{}, fmt.Errorf("bolt11 tagged field is truncated")
+		}
+
+		field := payload[i : i+length]
+		i += length
+
+		// Fixed length fields with the wrong length are skipped, as BOLT11
+		// requires, and the first valid payment hash is used
+		switch tag {
+		case fieldPaymentHash:
+			if b, ok := fixedLengthField(field, 52, 32); ok && paymentHash == nil {
+				paymentHash = b
+			}
+		case fieldPaymentSecret:
+			if b, ok := fixedLengthField(field, 52, 32); ok {
+				inv.PaymentSecret = b
+			}
+		case fieldDescriptionHash:
+			if b, ok := fixedLengthField(field, 52, 32); ok {
+				inv.DescriptionHash = b
+			}
+		case fieldPayee:
+			if b, ok := fixedLengthField(field, 53, 33); ok {
+				payee = b
+			}
+		case fieldDescription:
+			description, err := bech32.ConvertBits(field, 5, 8, false)
+			if err != nil {
+				return Invoice{}, fmt.Errorf("invalid bolt11 description: %w", err)
+			}
+
+			if !utf8.Valid(description) {
+				return Invoice{}, fmt.Errorf("bolt11 description is not valid utf-8")
+			}
+			inv.Description = string(description)
+		case fieldExpiry:
+			expiry = maxExpiry
+			if seconds := wordsToUint(field); len(field) <= 12 && seconds < uint64(maxExpiry/time.Second) {
+				expiry = time.Duration(seconds) * time.Second
+			}
+		case fieldMinFinalCLTVExpiry:
+			inv.MinFinalCLTVExpiryDelta = maxMinFinalCLTVExpiryDelta
+			if delta := wordsToUint(field); len(field) <= 12 && delta < maxMinFinalCLTVExpiryDelta {
+				inv.MinFinalCLTVExpiryDelta = delta
+			}
+		}
+	}
+
+	if paymentHash == nil {
+		return Invoice{}, fmt.Errorf("bolt11 invoice has no valid payment hash")
+	}
+
+	if inv.PaymentHash, err = blockchain.NewTransactionId(inv.ChainId, hex.EncodeToString(paymentHash)); err != nil {
+		return Invoice{}, err
+	}
+
+	inv.Expiry = types.Time{Time: timestamp.Add(expiry)}
+
+	key, err := recoverPayee(hrp, payload, signature)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if payee != nil && !bytes.Equal(payee, key.SerializeCompressed()) {
+		return Invoice{}, fmt.Errorf("bolt11 invoice is not signed by its payee: %w", errors.ErrInvalid)
+	}
+	inv.Payee = hex.EncodeToString(key.SerializeCompressed())
+
+	return inv, nil
+}
+
+// IsExpired reports whether the invoice has expired at the given time
+func (i Invoice) IsExpired(now time.Time) bool {
+	return !now.Before(i.Expiry.Time)
+}
+
+func decodeHRP(hrp string) (Invoice, error) {
+	match := hrpRegex.FindStringSubmatch(hrp)
+	if match == nil {
+		return Invoice{}, fmt.Errorf("invalid bolt11 prefix: %s", hrp)
+	}
+
+	prefix, digits, multiplier := match[1], match[2], match[3]
+	chainId, ok := networks[prefix]
+	if !ok {
+		return Invoice{}, fmt.Errorf("unknown bolt11 currency prefix: %s", prefix)
+	}
+
+	inv := Invoice{ChainId: chainId}
+	if digits == "" {
+		return inv, nil
+	}
+
+	// Pico bitcoin amounts must be whole millisatoshis
+	if multiplier == "p" && !strings.HasSuffix(digits, "0") {
+		return Invoice{}, fmt.Errorf("bolt11 amount is not a whole number of millisatoshis: %s", hrp)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Invoice{}, fmt.Errorf("invalid bolt11 amount: %s", hrp)
+	}
+
+	inv.Amount = &types.Decimal{Decimal: decimal.NewFromBigInt(value, multiplierExponents[multiplier])}
+	return inv, nil
+}
+
+// fixedLengthField converts a tagged field that must have a fixed number of
+// words into bytes, reporting false if the field is malformed
+func fixedLengthField(field []byte, words, size int) ([]byte, bool) {
+	if len(field) != words {
+		return nil, false
+	}
+
+	b, err := bech32.ConvertBits(field, 5, 8, false)
+	if err != nil || len(b) != size {
+		return nil, false
+	}
+
+	return b, true
+}
+
+func wordsToUint(words []byte) uint64 {
+	var n uint64
+	for _, w := range words {
+		n = n<<5 | uint64(w)
+	}
+	return n
+}
+
+// recoverPayee recovers the public key that signed the invoice, requiring the
+// signature to be in low-S form
+func recoverPayee(hrp string, payload, signature []byte) (*secp256k1.PublicKey, error) {
+	sig, err := bech32.ConvertBits(signature, 5, 8, false)
+	if err != nil || len(sig) != 65 {
+		return nil, fmt.Errorf("invalid bolt11 signature: %w", errors.ErrInvalid)
+	}
+
+	recoveryId := sig[64]
+	if recoveryId > 3 {
+		return nil, fmt.Errorf("invalid bolt11 signature recovery id: %w", errors.ErrInvalid)
+	}
+
+	var s secp256k1.ModNScalar
+	if overflow := s.SetByteSlice(sig[32:64]); overflow || s.IsOverHalfOrder() {
+		return nil, fmt.Errorf("bolt11 signature is not in low-S form: %w", errors.ErrInvalid)
+	}
+
+	data, err := bech32.ConvertBits(payload, 5, 8, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bolt11 invoice: %w", err)
+	}
+
+	hash := sha256.Sum256(append([]byte(hrp), data...))
+
+	// Compact signatures are prefixed with 27 + 4 for compressed keys plus the
+	// recovery id
+	compact := append([]byte{27 + 4 + recoveryId}, sig[:64]...)
+	key, _, err := ecdsa.RecoverCompact(compact, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid bolt11 signature: %v: %w", err, errors.ErrInvalid)
+	}
+
+	return key, nil
+}
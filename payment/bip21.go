@@ -0,0 +1,138 @@
+package payment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	bitcoinMainnet  = blockchain.ChainId{Namespace: "bip122", Reference: "000000000019d6689c085ae165831e93"}
+	bitcoinTestnet  = blockchain.ChainId{Namespace: "bip122", Reference: "000000000933ea01ad0ee984209779ba"}
+	bitcoinRegtest  = blockchain.ChainId{Namespace: "bip122", Reference: "0f9188f13cb7b2c71f2a335e3a4fc328"}
+	litecoinMainnet = blockchain.ChainId{Namespace: "bip122", Reference: "12a765e31ffd4059bada1e25190f6e98"}
+
+	// bip21Chains maps BIP-21 uri schemes to the chains an address is tried
+	// against in order. Bitcoin test networks share address prefixes, so
+	// testnet addresses are parsed as testnet3.
+	bip21Chains = map[string][]blockchain.ChainId{
+		"bitcoin":  {bitcoinMainnet, bitcoinTestnet, bitcoinRegtest},
+		"litecoin": {litecoinMainnet},
+	}
+
+	// bip21Schemes maps chains to their BIP-21 uri scheme. Testnet4 and signet
+	// are not included as their addresses would be parsed as testnet3.
+	bip21Schemes = map[blockchain.ChainId]string{
+		bitcoinMainnet:  "bitcoin",
+		bitcoinTestnet:  "bitcoin",
+		bitcoinRegtest:  "bitcoin",
+		litecoinMainnet: "litecoin",
+	}
+
+	// bip21AmountRegex matches decimal amounts with at most the 8 decimal
+	// places of a satoshi
+	bip21AmountRegex = regexp.MustCompile("^[0-9]*(\\.[0-9]{0,8})?$")
+)
+
+// bip21Decimals is the number of decimal places of BIP-21 amounts
+const bip21Decimals = 8
+
+// parseBIP21 parses the remainder of a BIP-21 uri
+func parseBIP21(scheme, rest string) (Request, error) {
+	address, query, _ := strings.Cut(rest, "?")
+
+	var r Request
+	var err error
+	if r.Recipient, err = bip21Recipient(scheme, address); err != nil {
+		return Request{}, err
+	}
+
+	if r.Asset, err = blockchain.NativeAsset(r.Recipient.ChainId); err != nil {
+		return Request{}, err
+	}
+
+	params, err := parseQuery(query)
+	if err != nil {
+		return Request{}, err
+	}
+
+	for _, p := range params {
+		switch p.key {
+		case "amount":
+			if p.value == "" || p.value == "." || !bip21AmountRegex.MatchString(p.value) {
+				return Request{}, fmt.Errorf("invalid bip21 amount: %s", p.value)
+			}
+
+			amount, err := decimal.NewFromString(p.value)
+			if err != nil {
+				return Request{}, fmt.Errorf("invalid bip21 amount %s: %w", p.value, err)
+			}
+			r.Amount = decimalPtr(types.Decimal{Decimal: amount})
+		case "label":
+			r.Label = p.value
+		case "message":
+			r.Message = p.value
+		default:
+			// BIP-21 requires uris with unknown required parameters to be rejected
+			if strings.HasPrefix(p.key, "req-") {
+				return Request{}, fmt.Errorf("unsupported bip21 required parameter: %s", p.key)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// bip21Recipient returns the account id of an address on the first chain of a
+// uri scheme it is valid on
+func bip21Recipient(scheme, address string) (blockchain.AccountId, error) {
+	var recipient blockchain.AccountId
+	var err error
+	for _, chainId := range bip21Chains[scheme] {
+		if recipient, err = blockchain.NewAccountId(chainId, address); err == nil {
+			return recipient, nil
+		}
+	}
+
+	return blockchain.AccountId{}, fmt.Errorf("invalid %s address %s: %w", scheme, address, err)
+}
+
+// formatBIP21 formats a BIP-21 uri for a payment of a chain's native asset. The
+// uri does not identify the chain, so chains whose addresses would be parsed as
+// another chain's, such as legacy regtest addresses, are not supported.
+func formatBIP21(r Request) (string, error) {
+	scheme, ok := bip21Schemes[r.Recipient.ChainId]
+	if !ok {
+		return "", fmt.Errorf("bip21 uris are not supported on chain %s: %w", r.Recipient.ChainId, errors.ErrUnsupported)
+	}
+
+	if recipient, err := bip21Recipient(scheme, r.Recipient.Address); err != nil || recipient.ChainId != r.Recipient.ChainId {
+		return "", fmt.Errorf("bip21 uri for %s would not identify chain %s: %w", r.Recipient.Address, r.Recipient.ChainId, errors.ErrUnsupported)
+	}
+
+	if r.Asset.Namespace != "slip44" {
+		return "", fmt.Errorf("bip21 uris are not supported for asset %s", r.Asset)
+	}
+
+	if r.Amount != nil && !r.Amount.Equal(r.Amount.Truncate(bip21Decimals)) {
+		return "", fmt.Errorf("bip21 amount has more than %d decimal places: %s", bip21Decimals, r.Amount)
+	}
+
+	var params []param
+	if r.Amount != nil {
+		params = append(params, param{"amount", r.Amount.String()})
+	}
+	if r.Label != "" {
+		params = append(params, param{"label", r.Label})
+	}
+	if r.Message != "" {
+		params = append(params, param{"message", r.Message})
+	}
+
+	return scheme + ":" + r.Recipient.Address + formatQuery(params), nil
+}
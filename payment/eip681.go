@@ -0,0 +1,201 @@
+package payment
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// etherCoinType and etherDecimals describe ether, assumed to be the native
+	// asset of eip155 chains whose native asset is not known
+	etherCoinType = 60
+	etherDecimals = 18
+)
+
+var (
+	eip681Regex       = regexp.MustCompile("^(?:pay-)?([^@/?]+)(?:@([0-9]+))?(?:/([^?]+))?(?:\\?(.*))?$")
+	eip681NumberRegex = regexp.MustCompile("^[0-9]*(\\.[0-9]+)?([eE][0-9]+)?$")
+)
+
+// parseEIP681 parses the remainder of an EIP-681 ethereum: uri, supporting
+// native transfers and ERC-20 transfer calls
+func parseEIP681(ctx context.Context, rest string, provider blockchain.AssetMetadataProvider) (Request, error) {
+	match := eip681Regex.FindStringSubmatch(rest)
+	if match == nil {
+		return Request{}, fmt.Errorf("invalid eip681 uri: ethereum:%s", rest)
+	}
+
+	target, chainReference, function, query := match[1], match[2], match[3], match[4]
+	if chainReference == "" {
+		chainReference = "1"
+	}
+
+	chainId, err := blockchain.NewChainId("eip155", chainReference)
+	if err != nil {
+		return Request{}, err
+	}
+
+	params, err := parseQuery(query)
+	if err != nil {
+		return Request{}, err
+	}
+
+	var r Request
+	var units string
+	switch function {
+	case "":
+		if r.Recipient, err = blockchain.NewAccountId(chainId, target); err != nil {
+			return Request{}, err
+		}
+
+		if r.Asset, err = eip681NativeAsset(chainId); err != nil {
+			return Request{}, err
+		}
+
+		for _, p := range params {
+			if p.key == "value" {
+				units = p.value
+			}
+		}
+	case "transfer":
+		if r.Asset, err = blockchain.NewERC20Asset(chainId, target); err != nil {
+			return Request{}, err
+		}
+
+		for _, p := range params {
+			switch p.key {
+			case "address":
+				if r.Recipient, err = blockchain.NewAccountId(chainId, p.value); err != nil {
+					return Request{}, err
+				}
+			case "uint256":
+				units = p.value
+			}
+		}
+
+		if r.Recipient == (blockchain.AccountId{}) {
+			return Request{}, fmt.Errorf("eip681 transfer has no address: ethereum:%s", rest)
+		}
+	default:
+		return Request{}, fmt.Errorf("unsupported eip681 function: %s", function)
+	}
+
+	if units != "" {
+		amount, err := parseEIP681Units(ctx, provider, r.Asset, units)
+		if err != nil {
+			return Request{}, err
+		}
+		r.Amount = &amount
+	}
+
+	return r, nil
+}
+
+func parseEIP681Units(ctx context.Context, provider blockchain.AssetMetadataProvider, asset blockchain.AssetId, units string) (types.Decimal, error) {
+	if units == "" || !eip681NumberRegex.MatchString(units) {
+		return types.Decimal{}, fmt.Errorf("invalid eip681 amount: %s", units)
+	}
+
+	value, err := decimal.NewFromString(units)
+	if err != nil {
+		return types.Decimal{}, fmt.Errorf("invalid eip681 amount %s: %w", units, err)
+	}
+
+	if !value.IsInteger() {
+		return types.Decimal{}, fmt.Errorf("eip681 amount is not a whole number of base units: %s", units)
+	}
+
+	decimals, err := eip681Decimals(ctx, provider, asset)
+	if err != nil {
+		return types.Decimal{}, err
+	}
+
+	amount := blockchain.NewAmountFromBaseUnits(asset, value.BigInt(), decimals)
+	return amount.Quantity, nil
+}
+
+// eip681NativeAsset returns the native asset of an eip155 chain, or ether if
+// the native asset of the chain is not known
+func eip681NativeAsset(chainId blockchain.ChainId) (blockchain.AssetId, error) {
+	asset, err := blockchain.NativeAsset(chainId)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		return blockchain.NewSLIP44Asset(chainId, etherCoinType)
+	}
+
+	return asset, err
+}
+
+// eip681Decimals resolves the decimals of an asset, falling back to the 18
+// decimals of ether for ether assets unknown to the provider
+func eip681Decimals(ctx context.Context, provider blockchain.AssetMetadataProvider, asset blockchain.AssetId) (int32, error) {
+	metadata, err := provider.AssetMetadata(ctx, asset)
+	if err == nil {
+		return metadata.Decimals, nil
+	}
+
+	if asset.Namespace == "slip44" && asset.Reference == strconv.Itoa(etherCoinType) {
+		return etherDecimals, nil
+	}
+
+	return 0, fmt.Errorf("resolving decimals of %s: %w", asset, err)
+}
+
+// formatEIP681 formats an EIP-681 ethereum: uri for a native transfer or an
+// ERC-20 transfer call, with addresses in their EIP-55 checksum form
+func formatEIP681(ctx context.Context, r Request, provider blockchain.AssetMetadataProvider) (string, error) {
+	recipient, err := r.Recipient.ChecksumAddress()
+	if err != nil {
+		return "", err
+	}
+
+	chain := ""
+	if r.Recipient.ChainId.Reference != "1" {
+		chain = "@" + r.Recipient.ChainId.Reference
+	}
+
+	var units string
+	if r.Amount != nil {
+		decimals, err := eip681Decimals(ctx, provider, r.Asset)
+		if err != nil {
+			return "", err
+		}
+
+		baseUnits, err := blockchain.NewAmount(r.Asset, *r.Amount).BaseUnits(decimals)
+		if err != nil {
+			return "", err
+		}
+		units = baseUnits.String()
+	}
+
+	switch r.Asset.Namespace {
+	case "slip44":
+		var params []param
+		if units != "" {
+			params = append(params, param{"value", units})
+		}
+
+		return "ethereum:" + recipient + chain + formatQuery(params), nil
+	case "erc20":
+		token, err := blockchain.AccountId{ChainId: r.Asset.ChainId, Address: r.Asset.Reference}.ChecksumAddress()
+		if err != nil {
+			return "", err
+		}
+
+		params := []param{{"address", recipient}}
+		if units != "" {
+			params = append(params, param{"uint256", units})
+		}
+
+		return "ethereum:" + token + chain + "/transfer" + formatQuery(params), nil
+	default:
+		return "", fmt.Errorf("eip681 uris are not supported for asset namespace %s", r.Asset.Namespace)
+	}
+}
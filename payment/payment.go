@@ -0,0 +1,150 @@
+// Package payment builds and parses payment request URIs, such as EIP-681
+// ethereum: URIs, BIP-21 bitcoin: URIs and Solana Pay solana: URLs
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/types"
+)
+
+// Request is a request for a payment to an account
+type Request struct {
+	// Recipient is the account to be paid
+	Recipient blockchain.AccountId
+	// Asset is the asset to be paid, which is the native asset of the
+	// recipient's chain if empty when formatting
+	Asset blockchain.AssetId
+	// Amount is the amount to be paid in display units, or nil if the payer
+	// chooses the amount
+	Amount *types.Decimal
+	// Label describes the recipient
+	Label string
+	// Message describes the payment
+	Message string
+	// Memo is the Solana Pay memo to include in the transaction
+	Memo string
+	// References are the Solana Pay reference accounts used to locate the
+	// transaction
+	References []string
+}
+
+// Parse parses a payment request URI. The provider resolves the decimals of
+// EIP-681 amounts, which are encoded in base units, and defaults to the slip44
+// provider for native assets when nil.
+func Parse(ctx context.Context, uri string, provider blockchain.AssetMetadataProvider) (Request, error) {
+	scheme, rest, ok := strings.Cut(uri, ":")
+	if !ok {
+		return Request{}, fmt.Errorf("invalid payment uri: %s", uri)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "ethereum":
+		return parseEIP681(ctx, rest, metadataProvider(provider))
+	case "solana":
+		return parseSolanaPay(rest)
+	default:
+		if _, ok := bip21Chains[strings.ToLower(scheme)]; ok {
+			return parseBIP21(strings.ToLower(scheme), rest)
+		}
+
+		return Request{}, fmt.Errorf("unsupported payment uri scheme: %s", scheme)
+	}
+}
+
+// Format builds the payment request URI of a request. The provider resolves the
+// decimals of EIP-681 amounts, which are encoded in base units, and defaults to
+// the slip44 provider for native assets when nil.
+func Format(ctx context.Context, r Request, provider blockchain.AssetMetadataProvider) (string, error) {
+	if r.Asset == (blockchain.AssetId{}) {
+		asset, err := blockchain.NativeAsset(r.Recipient.ChainId)
+		if err != nil {
+			return "", err
+		}
+		r.Asset = asset
+	}
+
+	if r.Asset.ChainId != r.Recipient.ChainId {
+		return "", fmt.Errorf("asset %s is not on the chain of recipient %s", r.Asset, r.Recipient)
+	}
+
+	if r.Amount != nil && r.Amount.Sign() < 0 {
+		return "", fmt.Errorf("payment amount is negative: %s", r.Amount)
+	}
+
+	switch r.Recipient.ChainId.Namespace {
+	case "eip155":
+		return formatEIP681(ctx, r, metadataProvider(provider))
+	case "bip122":
+		return formatBIP21(r)
+	case "solana":
+		return formatSolanaPay(r)
+	default:
+		return "", fmt.Errorf("payment uris are not supported in namespace %s", r.Recipient.ChainId.Namespace)
+	}
+}
+
+func metadataProvider(provider blockchain.AssetMetadataProvider) blockchain.AssetMetadataProvider {
+	if provider == nil {
+		return blockchain.SLIP44AssetMetadataProvider{}
+	}
+
+	return provider
+}
+
+type param struct {
+	key   string
+	value string
+}
+
+// parseQuery parses the parameters of a payment uri in order. Unlike
+// url.ParseQuery, a + is kept as is rather than decoded as a space.
+func parseQuery(query string) ([]param, error) {
+	if query == "" {
+		return nil, nil
+	}
+
+	var params []param
+	for _, pair := range strings.Split(query, "&") {
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.PathUnescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payment uri parameter %s: %w", pair, err)
+		}
+
+		value, err = url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payment uri parameter %s: %w", pair, err)
+		}
+
+		params = append(params, param{key, value})
+	}
+
+	return params, nil
+}
+
+// formatQuery formats parameters in order, percent encoding spaces as %20
+func formatQuery(params []param) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	encoded := make([]string, len(params))
+	for i, p := range params {
+		encoded[i] = escape(p.key) + "=" + escape(p.value)
+	}
+
+	return "?" + strings.Join(encoded, "&")
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func decimalPtr(d types.Decimal) *types.Decimal {
+	return &d
+}
@@ -0,0 +1,113 @@
+package payment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// solanaMainnet is the chain of Solana Pay transfer requests, which do not
+	// identify a cluster
+	solanaMainnet = blockchain.ChainId{Namespace: "solana", Reference: "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"}
+
+	solanaPayAmountRegex = regexp.MustCompile("^[0-9]+(\\.[0-9]+)?$")
+)
+
+// parseSolanaPay parses the remainder of a Solana Pay transfer request url.
+// Transaction requests, whose recipient is an https url, are not supported.
+func parseSolanaPay(rest string) (Request, error) {
+	recipient, query, _ := strings.Cut(rest, "?")
+	if strings.HasPrefix(recipient, "https") {
+		return Request{}, fmt.Errorf("solana pay transaction requests are not supported: solana:%s", rest)
+	}
+
+	var r Request
+	var err error
+	if r.Recipient, err = blockchain.NewAccountId(solanaMainnet, recipient); err != nil {
+		return Request{}, err
+	}
+
+	if r.Asset, err = blockchain.NativeAsset(solanaMainnet); err != nil {
+		return Request{}, err
+	}
+
+	params, err := parseQuery(query)
+	if err != nil {
+		return Request{}, err
+	}
+
+	for _, p := range params {
+		switch p.key {
+		case "amount":
+			if !solanaPayAmountRegex.MatchString(p.value) {
+				return Request{}, fmt.Errorf("invalid solana pay amount: %s", p.value)
+			}
+
+			amount, err := decimal.NewFromString(p.value)
+			if err != nil {
+				return Request{}, fmt.Errorf("invalid solana pay amount %s: %w", p.value, err)
+			}
+			r.Amount = decimalPtr(types.Decimal{Decimal: amount})
+		case "spl-token":
+			if r.Asset, err = blockchain.NewSPLAsset(solanaMainnet, p.value); err != nil {
+				return Request{}, err
+			}
+		case "reference":
+			if _, err := blockchain.NewAccountId(solanaMainnet, p.value); err != nil {
+				return Request{}, fmt.Errorf("invalid solana pay reference: %w", err)
+			}
+			r.References = append(r.References, p.value)
+		case "label":
+			r.Label = p.value
+		case "message":
+			r.Message = p.value
+		case "memo":
+			r.Memo = p.value
+		}
+	}
+
+	return r, nil
+}
+
+// formatSolanaPay formats a Solana Pay transfer request url for a payment of SOL
+// or an SPL token. The cluster is not encoded, so only mainnet requests are
+// supported.
+func formatSolanaPay(r Request) (string, error) {
+	if r.Recipient.ChainId != solanaMainnet {
+		return "", fmt.Errorf("solana pay urls are only supported on mainnet, not %s: %w", r.Recipient.ChainId, errors.ErrUnsupported)
+	}
+
+	var params []param
+	if r.Amount != nil {
+		params = append(params, param{"amount", r.Amount.String()})
+	}
+
+	switch r.Asset.Namespace {
+	case "slip44":
+	case "spl":
+		params = append(params, param{"spl-token", r.Asset.Reference})
+	default:
+		return "", fmt.Errorf("solana pay urls are not supported for asset namespace %s", r.Asset.Namespace)
+	}
+
+	for _, reference := range r.References {
+		params = append(params, param{"reference", reference})
+	}
+	if r.Label != "" {
+		params = append(params, param{"label", r.Label})
+	}
+	if r.Message != "" {
+		params = append(params, param{"message", r.Message})
+	}
+	if r.Memo != "" {
+		params = append(params, param{"memo", r.Memo})
+	}
+
+	return "solana:" + r.Recipient.Address + formatQuery(params), nil
+}
@@ -91,3 +91,83 @@ func TestAccountId(t *testing.T) {
 		}
 	}
 }
+
+func TestAccountIdEIP55(t *testing.T) {
+	for _, tc := range []struct {
+		id        string
+		canonical string
+		checksum  string
+		valid     bool
+	}{{
+		id:        "eip155:1:0xab16a96D359eC26a11e2C2b3d8f8B8942d5Bfcdb",
+		canonical: "eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb",
+		checksum:  "0xab16a96D359eC26a11e2C2b3d8f8B8942d5Bfcdb",
+		valid:     true,
+	}, {
+		id:        "eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		canonical: "eip155:1:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		checksum:  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		valid:     true,
+	}, {
+		id:        "eip155:137:0xFB6916095CA1DF60BB79CE92CE3EA74C37C5D359",
+		canonical: "eip155:137:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		checksum:  "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		valid:     true,
+	}, {
+		// Checksum with a single character case flipped
+		id:    "eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+		valid: false,
+	}, {
+		// 19 bytes
+		id:    "eip155:1:0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+		valid: false,
+	}, {
+		// Missing 0x prefix
+		id:    "eip155:1:5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		valid: false,
+	}} {
+		a, err := blockchain.ParseAccountId(tc.id)
+		if !tc.valid {
+			if err == nil {
+				t.Errorf("Parsed invalid account id %s", tc.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to parse account id %s: %v", tc.id, err)
+		}
+
+		if a.String() != tc.canonical {
+			t.Errorf("Account id not canonical: %s", a.String())
+		}
+
+		v, err := a.Value()
+		if err != nil || v != tc.canonical {
+			t.Errorf("Account id value not canonical: %v", v)
+		}
+
+		checksum, err := a.ChecksumAddress()
+		if err != nil {
+			t.Errorf("Failed to checksum address: %v", err)
+		}
+		if checksum != tc.checksum {
+			t.Errorf("Unexpected checksum address %s", checksum)
+		}
+
+		literal := blockchain.AccountId{ChainId: a.ChainId, Address: tc.checksum}
+		if !a.Equal(literal) {
+			t.Errorf("Case variants of the same address are not equal")
+		}
+	}
+
+	// Struct literals that fail validation are not written to the database
+	literal := blockchain.AccountId{ChainId: blockchain.MustParseChainId("eip155:1"), Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"}
+	if _, err := literal.Value(); err == nil {
+		t.Errorf("Wrote invalid account id literal %s", literal)
+	}
+
+	a := blockchain.MustParseAccountId("eip155:1:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	if a.Equal(blockchain.MustParseAccountId("eip155:137:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")) {
+		t.Errorf("Accounts on different chains are equal")
+	}
+}
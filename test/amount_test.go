@@ -0,0 +1,137 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ether = blockchain.MustParseAssetId("eip155:1/slip44:60")
+	usdc  = blockchain.MustParseAssetId("eip155:1/erc20:0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+)
+
+func amount(asset blockchain.AssetId, s string) blockchain.Amount {
+	return blockchain.NewAmount(asset, types.Decimal{Decimal: decimal.RequireFromString(s)})
+}
+
+func TestAmountBaseUnits(t *testing.T) {
+	for _, tc := range []struct {
+		units    string
+		decimals int32
+		quantity string
+	}{{
+		units:    "1500000000000000000",
+		decimals: 18,
+		quantity: "1.5",
+	}, {
+		units:    "1",
+		decimals: 18,
+		quantity: "0.000000000000000001",
+	}, {
+		units:    "2100000000000000",
+		decimals: 8,
+		quantity: "21000000",
+	}, {
+		units:    "-1234567",
+		decimals: 6,
+		quantity: "-1.234567",
+	}, {
+		units:    "42",
+		decimals: 0,
+		quantity: "42",
+	}} {
+		units, ok := new(big.Int).SetString(tc.units, 10)
+		require.True(t, ok)
+
+		a := blockchain.NewAmountFromBaseUnits(ether, units, tc.decimals)
+		require.True(t, a.Quantity.Equal(decimal.RequireFromString(tc.quantity)), "got %s", a.Quantity)
+
+		back, err := a.BaseUnits(tc.decimals)
+		require.NoError(t, err)
+		require.Equal(t, 0, back.Cmp(units))
+	}
+
+	// Nil units are zero
+	require.True(t, blockchain.NewAmountFromBaseUnits(ether, nil, 18).IsZero())
+}
+
+func TestAmountBaseUnitsPrecision(t *testing.T) {
+	_, err := amount(usdc, "1.0000001").BaseUnits(6)
+	require.Error(t, err)
+
+	units, err := amount(usdc, "1.000000").BaseUnits(6)
+	require.NoError(t, err)
+	require.Equal(t, "1000000", units.String())
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	sum, err := amount(ether, "1.5").Add(amount(ether, "0.25"))
+	require.NoError(t, err)
+	require.Equal(t, "1.75 eip155:1/slip44:60", sum.String())
+
+	diff, err := amount(ether, "1.5").Sub(amount(ether, "2"))
+	require.NoError(t, err)
+	require.True(t, diff.IsNegative())
+	require.Equal(t, "0.5", diff.Neg().Quantity.String())
+
+	cmp, err := amount(ether, "1").Cmp(amount(ether, "1.00"))
+	require.NoError(t, err)
+	require.Equal(t, 0, cmp)
+
+	_, err = amount(ether, "1").Add(amount(usdc, "1"))
+	require.True(t, errors.Is(err, blockchain.ErrAssetMismatch))
+
+	_, err = amount(ether, "1").Sub(amount(usdc, "1"))
+	require.True(t, errors.Is(err, blockchain.ErrAssetMismatch))
+
+	_, err = amount(ether, "1").Cmp(amount(usdc, "1"))
+	require.True(t, errors.Is(err, blockchain.ErrAssetMismatch))
+}
+
+func TestAmountEncoding(t *testing.T) {
+	a := amount(usdc, "-12.345")
+	s := "-12.345 eip155:1/erc20:0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	require.Equal(t, s, a.String())
+
+	parsed, err := blockchain.ParseAmount(s)
+	require.NoError(t, err)
+	require.Equal(t, a.Asset, parsed.Asset)
+	require.True(t, a.Quantity.Equal(parsed.Quantity.Decimal))
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"asset":"eip155:1/erc20:0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","quantity":"-12.345"}`, string(data))
+
+	var unmarshalled blockchain.Amount
+	require.NoError(t, json.Unmarshal(data, &unmarshalled))
+	require.Equal(t, a.String(), unmarshalled.String())
+
+	pb, err := a.MarshalProto()
+	require.NoError(t, err)
+	var fromProto blockchain.Amount
+	require.NoError(t, fromProto.UnmarshalProto(pb))
+	require.Equal(t, a.String(), fromProto.String())
+
+	value, err := a.Value()
+	require.NoError(t, err)
+	var scanned blockchain.Amount
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, a.String(), scanned.String())
+
+	for _, invalid := range []string{
+		"",
+		"1.5",
+		"abc eip155:1/slip44:60",
+		"1.5 eip155:1",
+	} {
+		_, err := blockchain.ParseAmount(invalid)
+		require.Error(t, err, invalid)
+	}
+}
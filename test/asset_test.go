@@ -1,7 +1,9 @@
 package test
 
 import (
+	"bytes"
 	"encoding/json"
+	"strconv"
 	"testing"
 
 	"github.com/offblocks/offblocks-common/blockchain"
@@ -11,6 +13,8 @@ import (
 func TestAssetId(t *testing.T) {
 	for _, tc := range []struct {
 		id string
+		// canonical is the normalised form of the id, if it differs
+		canonical string
 	}{{
 		// Ether Token
 		id: "eip155:1/slip44:60",
@@ -40,17 +44,23 @@ func TestAssetId(t *testing.T) {
 		id: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
 	}, {
 		// CryptoKitties Collectible
-		id: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d",
+		id:        "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d",
+		canonical: "eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d",
 	}, {
 		// CryptoKitties Collectible ID
-		id: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769",
+		id:        "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769",
+		canonical: "eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d/771769",
 	}} {
+		if tc.canonical == "" {
+			tc.canonical = tc.id
+		}
+
 		a := blockchain.AssetId{}
 		if err := a.Parse(tc.id); err != nil {
 			t.Fatalf("Failed to parse asset id: %v", err)
 		}
 
-		if a.String() != tc.id {
+		if a.String() != tc.canonical {
 			t.Fatalf("Failed to serialize asset id to string")
 		}
 
@@ -68,7 +78,7 @@ func TestAssetId(t *testing.T) {
 			t.Errorf("Failed to unmarshal from text")
 		}
 
-		if a.String() != tc.id {
+		if a.String() != tc.canonical {
 			t.Errorf("Unmarshalled asset id invalid")
 		}
 
@@ -92,7 +102,7 @@ func TestAssetId(t *testing.T) {
 			t.Errorf("Failed to unmarshal from proto")
 		}
 
-		if a.String() != tc.id {
+		if a.String() != tc.canonical {
 			t.Fatalf("Unmarshalled asset id invalid")
 		}
 
@@ -106,3 +116,231 @@ func TestAssetId(t *testing.T) {
 		}
 	}
 }
+
+func TestAssetIdTokenId(t *testing.T) {
+	id := "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769"
+
+	a, err := blockchain.ParseAssetId(id)
+	if err != nil {
+		t.Fatalf("Failed to parse asset id: %v", err)
+	}
+
+	if a.Reference != "0x06012c8cf97bead5deae237070f9587f8e7a266d" || a.TokenId != "771769" {
+		t.Fatalf("Token id not split from reference: %#v", a)
+	}
+
+	if a.IsFungible() {
+		t.Errorf("Token asset id reported as fungible")
+	}
+
+	collection := a.Collection()
+	if collection.IsFungible() || collection.String() != "eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d" {
+		t.Errorf("Unexpected collection asset id %s", collection)
+	}
+
+	created, err := blockchain.NewTokenAssetId(a.ChainId, a.Namespace, a.Reference, a.TokenId)
+	if err != nil {
+		t.Fatalf("Failed to create token asset id: %v", err)
+	}
+	if created != a {
+		t.Errorf("Created token asset id does not match parsed asset id")
+	}
+
+	if !blockchain.MustParseAssetId("eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f").IsFungible() {
+		t.Errorf("ERC-20 asset id reported as non-fungible")
+	}
+
+	if blockchain.MustParseAssetId("eip155:1/nft:collection/1").IsFungible() {
+		t.Errorf("Token asset id in unregistered namespace reported as fungible")
+	}
+
+	if _, err := blockchain.NewTokenAssetId(a.ChainId, a.Namespace, a.Reference, ""); err == nil {
+		t.Errorf("Created token asset id without token id")
+	}
+
+	if _, err := blockchain.ParseAssetId(collection.String() + "/"); err == nil {
+		t.Errorf("Parsed asset id with empty token id")
+	}
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("Failed to marshal to json: %v", err)
+	}
+
+	var decoded blockchain.AssetId
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded != a {
+		t.Errorf("Failed to round trip through json: %v", err)
+	}
+
+	pb, err := a.MarshalProto()
+	if err != nil {
+		t.Fatalf("Failed to marshal to proto: %v", err)
+	}
+
+	decoded = blockchain.AssetId{}
+	if err := decoded.UnmarshalProto(pb); err != nil || decoded != a {
+		t.Errorf("Failed to round trip through proto: %v", err)
+	}
+
+	v, err := a.Value()
+	if err != nil {
+		t.Fatalf("Failed to get sql value: %v", err)
+	}
+
+	decoded = blockchain.AssetId{}
+	if err := decoded.Scan(v); err != nil || decoded != a {
+		t.Errorf("Failed to round trip through sql: %v", err)
+	}
+
+	var gql bytes.Buffer
+	a.MarshalGQL(&gql)
+
+	unquoted, err := strconv.Unquote(gql.String())
+	if err != nil {
+		t.Fatalf("Failed to unquote gql: %v", err)
+	}
+
+	decoded = blockchain.AssetId{}
+	if err := decoded.UnmarshalGQL(unquoted); err != nil || decoded != a {
+		t.Errorf("Failed to round trip through gql: %v", err)
+	}
+}
+
+func TestAssetNamespaceValidation(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		valid bool
+	}{{
+		id:    "eip155:1/slip44:60",
+		valid: true,
+	}, {
+		id:    "eip155:1/slip44:sixty",
+		valid: false,
+	}, {
+		id:    "eip155:1/slip44:060",
+		valid: false,
+	}, {
+		id:    "eip155:1/erc20:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		valid: true,
+	}, {
+		// Invalid checksum
+		id:    "eip155:1/erc20:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eb48",
+		valid: false,
+	}, {
+		id:    "eip155:1/erc20:0xa0b86991",
+		valid: false,
+	}, {
+		// ERC-20 is only legal on eip155 chains
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/erc20:0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+		valid: false,
+	}, {
+		id:    "eip155:1/erc1155:0x28959Cf125ccB051E70711D0924a62FB28EAF186/0",
+		valid: true,
+	}, {
+		// Only non-fungible namespaces have token ids
+		id:    "eip155:1/erc20:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48/1",
+		valid: false,
+	}, {
+		id:    "eip155:1/slip44:60/1",
+		valid: false,
+	}, {
+		id:    "fiat:0/iso4217:USD/1",
+		valid: false,
+	}, {
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		valid: true,
+	}, {
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wE",
+		valid: false,
+	}, {
+		id:    "cosmos:osmosis-1/ibc:27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		valid: true,
+	}, {
+		id:    "cosmos:osmosis-1/ibc:27394fb092d2eccd56123c74f36e4c1f926001ceada9ca97ea622b25f41e5eb2",
+		valid: false,
+	}, {
+		id:    "eip155:1/ibc:27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		valid: false,
+	}} {
+		_, err := blockchain.ParseAssetId(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse asset id %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed invalid asset id %s", tc.id)
+		}
+	}
+
+	// Struct literals that fail validation are not written to the database
+	literal := blockchain.AssetId{ChainId: blockchain.MustParseChainId("eip155:1"), Namespace: "erc20", Reference: "0x1234"}
+	if _, err := literal.Value(); err == nil {
+		t.Errorf("Wrote invalid asset id literal %s", literal)
+	}
+}
+
+func TestAssetConstructors(t *testing.T) {
+	ethereum := blockchain.MustParseChainId("eip155:1")
+	solana := blockchain.MustParseChainId("solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp")
+	osmosis := blockchain.MustParseChainId("cosmos:osmosis-1")
+
+	for _, tc := range []struct {
+		create func() (blockchain.AssetId, error)
+		id     string
+	}{{
+		create: func() (blockchain.AssetId, error) { return blockchain.NewSLIP44Asset(ethereum, 60) },
+		id:     "eip155:1/slip44:60",
+	}, {
+		create: func() (blockchain.AssetId, error) {
+			return blockchain.NewERC20Asset(ethereum, "0x6b175474e89094c44da98b954eedeac495271d0f")
+		},
+		id: "eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f",
+	}, {
+		create: func() (blockchain.AssetId, error) {
+			return blockchain.NewERC721Asset(ethereum, "0x06012c8cf97BEaD5deAe237070F9587f8E7A266d")
+		},
+		id: "eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d",
+	}, {
+		create: func() (blockchain.AssetId, error) {
+			return blockchain.NewERC1155Asset(ethereum, "0x28959Cf125ccB051E70711D0924a62FB28EAF186")
+		},
+		id: "eip155:1/erc1155:0x28959cf125ccb051e70711d0924a62fb28eaf186",
+	}, {
+		create: func() (blockchain.AssetId, error) {
+			return blockchain.NewSPLAsset(solana, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+		},
+		id: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+	}, {
+		create: func() (blockchain.AssetId, error) {
+			return blockchain.NewIBCAsset(osmosis, "27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2")
+		},
+		id: "cosmos:osmosis-1/ibc:27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+	}} {
+		a, err := tc.create()
+		if err != nil {
+			t.Fatalf("Failed to create asset id %s: %v", tc.id, err)
+		}
+
+		if a.String() != tc.id {
+			t.Errorf("Unexpected asset id %s", a)
+		}
+	}
+
+	if _, err := blockchain.NewERC20Asset(solana, "0x6b175474e89094c44da98b954eedeac495271d0f"); err == nil {
+		t.Errorf("Created erc20 asset on solana chain")
+	}
+}
+
+func TestRegisterAssetNamespace(t *testing.T) {
+	blockchain.RegisterAssetNamespace("testtok", blockchain.AssetNamespace{
+		ChainNamespaces: []string{"eip155"},
+	})
+	t.Cleanup(func() { blockchain.UnregisterAssetNamespace("testtok") })
+
+	if _, err := blockchain.ParseAssetId("eip155:1/testtok:anything"); err != nil {
+		t.Errorf("Failed to parse asset id in registered namespace: %v", err)
+	}
+
+	if _, err := blockchain.ParseAssetId("cosmos:osmosis-1/testtok:anything"); err == nil {
+		t.Errorf("Parsed asset id on chain namespace not allowed by registration")
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+// See: https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki#test-vectors
+// See: https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki#test-vectors
+func TestBitcoinAddress(t *testing.T) {
+	for _, tc := range []struct {
+		id         string
+		canonical  string
+		scriptType blockchain.BitcoinScriptType
+		valid      bool
+	}{{
+		id:         "bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		scriptType: blockchain.BitcoinP2PKH,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000019d6689c085ae165831e93:3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+		scriptType: blockchain.BitcoinP2SH,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000019d6689c085ae165831e93:BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+		canonical:  "bip122:000000000019d6689c085ae165831e93:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		scriptType: blockchain.BitcoinP2WPKH,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000019d6689c085ae165831e93:bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+		scriptType: blockchain.BitcoinP2WSH,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000019d6689c085ae165831e93:bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+		scriptType: blockchain.BitcoinP2TR,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000933ea01ad0ee984209779ba:tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+		scriptType: blockchain.BitcoinP2WSH,
+		valid:      true,
+	}, {
+		id:         "bip122:000000000933ea01ad0ee984209779ba:mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn",
+		scriptType: blockchain.BitcoinP2PKH,
+		valid:      true,
+	}, {
+		// Mainnet address on testnet
+		id:    "bip122:000000000933ea01ad0ee984209779ba:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		valid: false,
+	}, {
+		// Testnet address on mainnet
+		id:    "bip122:000000000019d6689c085ae165831e93:tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+		valid: false,
+	}, {
+		// Invalid Base58Check checksum
+		id:    "bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p7",
+		valid: false,
+	}, {
+		// Segwit v0 with Bech32m checksum
+		id:    "bip122:000000000019d6689c085ae165831e93:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+		valid: false,
+	}, {
+		// Mixed case
+		id:    "bip122:000000000019d6689c085ae165831e93:bc1qW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		valid: false,
+	}} {
+		a, err := blockchain.ParseAccountId(tc.id)
+		if !tc.valid {
+			if err == nil {
+				t.Errorf("Parsed invalid account id %s", tc.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to parse account id %s: %v", tc.id, err)
+		}
+
+		canonical := tc.canonical
+		if canonical == "" {
+			canonical = tc.id
+		}
+		if a.String() != canonical {
+			t.Errorf("Account id not canonical: %s", a.String())
+		}
+
+		address, err := a.BitcoinAddress()
+		if err != nil {
+			t.Fatalf("Failed to decode bitcoin address %s: %v", tc.id, err)
+		}
+
+		if address.ScriptType != tc.scriptType {
+			t.Errorf("Unexpected script type %s for %s", address.ScriptType, tc.id)
+		}
+	}
+}
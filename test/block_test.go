@@ -0,0 +1,128 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+func TestBlockId(t *testing.T) {
+	for _, tc := range []struct {
+		id string
+	}{{
+		// Ethereum mainnet block number
+		id: "eip155:1:19000000",
+	}, {
+		// Ethereum mainnet block number and hash
+		id: "eip155:1:19000000:0xcf384012b91b081230cdf17a3f7dd370d8e67056058af6b272b3d54aa2714fac",
+	}, {
+		// Bitcoin genesis block
+		id: "bip122:000000000019d6689c085ae165831e93:0:000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+	}, {
+		// Ethereum mainnet block hash without a number
+		id: "eip155:1::0xcf384012b91b081230cdf17a3f7dd370d8e67056058af6b272b3d54aa2714fac",
+	}, {
+		// Solana mainnet blockhash
+		id: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp::EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+	}} {
+		b := blockchain.BlockId{}
+		if err := b.Parse(tc.id); err != nil {
+			t.Errorf("Failed to parse block id: %v", err)
+		}
+
+		if b.String() != tc.id {
+			t.Errorf("Failed to serialize block id to string")
+		}
+
+		if b.HasNumber {
+			if _, err := blockchain.NewBlockId(b.ChainId, b.Number, b.Hash); err != nil {
+				t.Errorf("Failed to create block id from number and hash")
+			}
+		} else {
+			if _, err := blockchain.NewBlockIdFromHash(b.ChainId, b.Hash); err != nil {
+				t.Errorf("Failed to create block id from hash")
+			}
+		}
+
+		text, err := b.MarshalText()
+		if err != nil {
+			t.Errorf("Failed to marshal to text")
+		}
+
+		b = blockchain.BlockId{}
+		if err := b.UnmarshalText(text); err != nil {
+			t.Errorf("Failed to unmarshal from text")
+		}
+
+		if b.String() != tc.id {
+			t.Errorf("Unmarshalled block id invalid")
+		}
+
+		text, err = json.Marshal(b)
+		if err != nil {
+			t.Errorf("Failed to marshal to json")
+		}
+
+		b = blockchain.BlockId{}
+		if err := json.Unmarshal(text, &b); err != nil {
+			t.Errorf("Failed to unmarshal to json")
+		}
+
+		pb, err := b.MarshalProto()
+		if err != nil {
+			t.Errorf("Failed to marshal to proto")
+		}
+
+		b = blockchain.BlockId{}
+		if err := b.UnmarshalProto(pb); err != nil {
+			t.Errorf("Failed to unmarshal from proto")
+		}
+
+		if b.String() != tc.id {
+			t.Errorf("Unmarshalled block id invalid")
+		}
+
+		b2 := blockchain.BlockId{}
+		if err := b2.Scan(b.String()); err != nil {
+			t.Errorf("Scanning value from sql.NullString")
+		}
+
+		if !b2.Equal(b) {
+			t.Errorf("Scanned value not valid")
+		}
+	}
+
+	for _, id := range []string{
+		"eip155:1",
+		"eip155:1:latest",
+		"eip155:1:-1",
+		"eip155:1:19000000:",
+		"eip155:1:19000000:0xcf38!",
+		"eip155:1:",
+		"eip155:1::",
+		// Hashes are validated for the chain namespace
+		"eip155:1:19000000:0xcf38",
+		// A transaction signature is not a blockhash
+		"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp::3QorZbZ5bRWePAqRsAW5vMLggKfaJQ7RTdKEoXQBsWtgunjrQAN8wrj99yLDqAQassU7DzVYdB62rygzKQd7m7fU",
+	} {
+		if _, err := blockchain.ParseBlockId(id); err == nil {
+			t.Errorf("Parsed invalid block id %s", id)
+		}
+	}
+
+	b, err := blockchain.ParseBlockId("eip155:1:19000000:0xCF384012B91B081230CDF17A3F7DD370D8E67056058AF6B272B3D54AA2714FAC")
+	if err != nil {
+		t.Fatalf("Failed to parse block id: %v", err)
+	}
+
+	if b.Hash != "0xcf384012b91b081230cdf17a3f7dd370d8e67056058af6b272b3d54aa2714fac" {
+		t.Errorf("Block hash not normalised: %s", b.Hash)
+	}
+
+	// Block ids are comparable and can be used as map keys
+	seen := map[blockchain.BlockId]bool{b: true}
+	if !seen[blockchain.MustParseBlockId("eip155:1:19000000:0xcf384012b91b081230cdf17a3f7dd370d8e67056058af6b272b3d54aa2714fac")] {
+		t.Errorf("Equal block ids are different map keys")
+	}
+}
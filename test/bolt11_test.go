@@ -0,0 +1,251 @@
+package test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/bech32"
+	"github.com/offblocks/offblocks-common/lightning"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	bolt11Key   = secp256k1.PrivKeyFromBytes(mustDecodeHex("e126f68f7eafcc8b74f54d269fe206be715000f94dac067d1c04a8ca3b2db734"))
+	bolt11Other = secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{0x42}, 32))
+
+	bolt11PaymentHash = mustDecodeHex("0001020304050607080900010203040506070809000102030405060708090102")
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type bolt11Field struct {
+	tag   byte
+	words []byte
+}
+
+func bolt11BytesField(tag byte, b []byte) bolt11Field {
+	words, _ := bech32.ConvertBits(b, 8, 5, true)
+	return bolt11Field{tag, words}
+}
+
+func bolt11UintField(tag byte, n uint64) bolt11Field {
+	var words []byte
+	for ; n > 0; n >>= 5 {
+		words = append([]byte{byte(n & 31)}, words...)
+	}
+	return bolt11Field{tag, words}
+}
+
+// encodeBOLT11 builds and signs an invoice, optionally replacing the s value of
+// the signature with its high form
+func encodeBOLT11(t *testing.T, hrp string, timestamp uint64, fields []bolt11Field, key *secp256k1.PrivateKey, highS bool) string {
+	t.Helper()
+
+	words := make([]byte, 7)
+	for i := 6; i >= 0; i-- {
+		words[i] = byte(timestamp & 31)
+		timestamp >>= 5
+	}
+
+	for _, f := range fields {
+		words = append(words, f.tag, byte(len(f.words)>>5), byte(len(f.words)&31))
+		words = append(words, f.words...)
+	}
+
+	data, err := bech32.ConvertBits(words, 5, 8, true)
+	require.NoError(t, err)
+	hash := sha256.Sum256(append([]byte(hrp), data...))
+
+	compact := ecdsa.SignCompact(key, hash[:], true)
+	recoveryId := compact[0] - 27 - 4
+	sig := append(compact[1:], recoveryId)
+
+	if highS {
+		var s secp256k1.ModNScalar
+		s.SetByteSlice(sig[32:64])
+		s.Negate()
+		b := s.Bytes()
+		copy(sig[32:64], b[:])
+		sig[64] ^= 1
+	}
+
+	sigWords, err := bech32.ConvertBits(sig, 8, 5, true)
+	require.NoError(t, err)
+
+	invoice, err := bech32.Encode(hrp, append(words, sigWords...), bech32.Bech32)
+	require.NoError(t, err)
+	return invoice
+}
+
+func TestBOLT11Decode(t *testing.T) {
+	invoice := encodeBOLT11(t, "lnbc2500u", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash),
+		bolt11BytesField(16, bytes.Repeat([]byte{0x11}, 32)),
+		bolt11BytesField(13, []byte("1 cup coffee")),
+		bolt11UintField(6, 60),
+	}, bolt11Key, false)
+
+	inv, err := lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.Equal(t, "bip122:000000000019d6689c085ae165831e93", inv.ChainId.String())
+	require.Equal(t, "03e7156ae33b0a208d0744199163177e909e80176e55d97a2f221ede0f934dd9ad", inv.Payee)
+	require.True(t, inv.Amount.Equal(decimal.RequireFromString("0.0025")))
+	require.Equal(t, time.Unix(1496314658, 0).UTC(), inv.Timestamp.Time)
+	require.Equal(t, time.Unix(1496314658+60, 0).UTC(), inv.Expiry.Time)
+	require.Equal(t, "1 cup coffee", inv.Description)
+	require.Equal(t, "bip122:000000000019d6689c085ae165831e93:"+hex.EncodeToString(bolt11PaymentHash), inv.PaymentHash.String())
+	require.Equal(t, bytes.Repeat([]byte{0x11}, 32), inv.PaymentSecret)
+	require.Equal(t, uint64(18), inv.MinFinalCLTVExpiryDelta)
+	require.True(t, inv.IsExpired(time.Unix(1496314658+60, 0)))
+	require.False(t, inv.IsExpired(time.Unix(1496314658+59, 0)))
+
+	// Invoices may be prefixed with the lightning: scheme and are case insensitive
+	upper, err := lightning.Decode("LIGHTNING:" + strings.ToUpper(invoice))
+	require.NoError(t, err)
+	require.Equal(t, inv, upper)
+}
+
+func TestBOLT11DecodeOptionalFields(t *testing.T) {
+	descriptionHash := sha256.Sum256([]byte("One piece of chocolate cake, one icecream cone"))
+	invoice := encodeBOLT11(t, "lntb", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash),
+		bolt11BytesField(23, descriptionHash[:]),
+		bolt11BytesField(19, bolt11Key.PubKey().SerializeCompressed()),
+		bolt11UintField(24, 144),
+		// Unknown fields are skipped
+		bolt11BytesField(31, []byte("unknown")),
+	}, bolt11Key, false)
+
+	inv, err := lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.Equal(t, "bip122:000000000933ea01ad0ee984209779ba", inv.ChainId.String())
+	require.Nil(t, inv.Amount)
+	require.Equal(t, descriptionHash[:], inv.DescriptionHash)
+	require.Equal(t, uint64(144), inv.MinFinalCLTVExpiryDelta)
+	require.Equal(t, time.Unix(1496314658+3600, 0).UTC(), inv.Expiry.Time)
+
+	// Pico bitcoin amounts are whole millisatoshis
+	invoice = encodeBOLT11(t, "lnbcrt10p", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash),
+	}, bolt11Key, false)
+
+	inv, err = lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.True(t, inv.Amount.Equal(decimal.RequireFromString("0.00000000001")))
+}
+
+func TestBOLT11DecodeMalformedFields(t *testing.T) {
+	// Fixed length fields with the wrong length are skipped
+	invoice := encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash[:31]),
+		bolt11BytesField(1, bolt11PaymentHash),
+		bolt11BytesField(16, bytes.Repeat([]byte{0x11}, 31)),
+		bolt11BytesField(23, bytes.Repeat([]byte{0x22}, 33)),
+		bolt11BytesField(19, bolt11Other.PubKey().SerializeCompressed()[:32]),
+	}, bolt11Key, false)
+
+	inv, err := lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.Equal(t, "bip122:000000000019d6689c085ae165831e93:"+hex.EncodeToString(bolt11PaymentHash), inv.PaymentHash.String())
+	require.Nil(t, inv.PaymentSecret)
+	require.Nil(t, inv.DescriptionHash)
+	require.Equal(t, hex.EncodeToString(bolt11Key.PubKey().SerializeCompressed()), inv.Payee)
+
+	// Expiries too long to represent are clamped rather than overflowing
+	invoice = encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash),
+		bolt11UintField(6, 1<<63),
+		bolt11UintField(24, 1<<63),
+	}, bolt11Key, false)
+
+	inv, err = lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.True(t, inv.Expiry.After(inv.Timestamp.Time))
+	require.False(t, inv.IsExpired(time.Unix(1496314658, 0).AddDate(99, 0, 0)))
+	require.Equal(t, uint64(math.MaxUint32), inv.MinFinalCLTVExpiryDelta)
+
+	// Fields longer than 64 bits are clamped rather than wrapping around
+	invoice = encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{
+		bolt11BytesField(1, bolt11PaymentHash),
+		{24, append([]byte{1}, make([]byte, 13)...)},
+	}, bolt11Key, false)
+
+	inv, err = lightning.Decode(invoice)
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint32), inv.MinFinalCLTVExpiryDelta)
+}
+
+func TestBOLT11DecodeInvalid(t *testing.T) {
+	paymentHash := bolt11BytesField(1, bolt11PaymentHash)
+
+	// The payee field does not match the signing key
+	forged := encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{
+		paymentHash,
+		bolt11BytesField(19, bolt11Key.PubKey().SerializeCompressed()),
+	}, bolt11Other, false)
+	_, err := lightning.Decode(forged)
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+
+	// High-S signatures are malleable and rejected
+	malleated := encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{paymentHash}, bolt11Key, true)
+	_, err = lightning.Decode(malleated)
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+
+	// Changing the amount invalidates the signature of an invoice with a payee
+	valid := encodeBOLT11(t, "lnbc1m", 1496314658, []bolt11Field{
+		paymentHash,
+		bolt11BytesField(19, bolt11Key.PubKey().SerializeCompressed()),
+	}, bolt11Key, false)
+	_, err = lightning.Decode(valid)
+	require.NoError(t, err)
+
+	_, words, _, err := bech32.Decode(valid, 0)
+	require.NoError(t, err)
+	tampered, err := bech32.Encode("lnbc2m", words, bech32.Bech32)
+	require.NoError(t, err)
+	_, err = lightning.Decode(tampered)
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+
+	for _, tc := range []struct {
+		hrp    string
+		fields []bolt11Field
+	}{{
+		// Sub-millisatoshi amount
+		hrp:    "lnbc1p",
+		fields: []bolt11Field{paymentHash},
+	}, {
+		// Unknown currency
+		hrp:    "lnxx1m",
+		fields: []bolt11Field{paymentHash},
+	}, {
+		// Missing payment hash
+		hrp:    "lnbc1m",
+		fields: []bolt11Field{bolt11BytesField(13, []byte("coffee"))},
+	}, {
+		// The only payment hash has the wrong length
+		hrp:    "lnbc1m",
+		fields: []bolt11Field{bolt11BytesField(1, bolt11PaymentHash[:31])},
+	}} {
+		_, err := lightning.Decode(encodeBOLT11(t, tc.hrp, 1496314658, tc.fields, bolt11Key, false))
+		require.Error(t, err, tc.hrp)
+	}
+
+	_, err = lightning.Decode("lnbc1qqqqqqq")
+	require.Error(t, err)
+}
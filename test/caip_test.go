@@ -0,0 +1,239 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+// Conformance suite built from the examples in the CAIP specifications
+// See: https://github.com/ChainAgnostic/CAIPs/blob/master/CAIPs/caip-2.md
+// See: https://github.com/ChainAgnostic/CAIPs/blob/master/CAIPs/caip-10.md
+// See: https://github.com/ChainAgnostic/CAIPs/blob/master/CAIPs/caip-19.md
+func TestCAIPConformance(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		parse func(string) error
+		valid bool
+	}{{
+		id:    "starknet:SN_GOERLI",
+		parse: parseChainId,
+		valid: true,
+	}, {
+		id:    "eip155!!:1",
+		parse: parseChainId,
+		valid: false,
+	}, {
+		id:    "EIP155:1",
+		parse: parseChainId,
+		valid: false,
+	}, {
+		id:    "ei:1",
+		parse: parseChainId,
+		valid: false,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4a",
+		parse: parseChainId,
+		valid: false,
+	}, {
+		id:    "chainstd:",
+		parse: parseChainId,
+		valid: false,
+	}, {
+		id:    "starknet:SN_GOERLI:0x02dd1b492765c064eac4039e3841aa5f382773b598097a40073bd8b48170ab57",
+		parse: parseAccountId,
+		valid: true,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:6d9b0b4b9994e8a6afbd3dc3ed983cd51c755afb27cd1dc7825ef59c134a39f7",
+		parse: parseAccountId,
+		valid: true,
+	}, {
+		id:    "hedera:mainnet:0.0.1234567890-zbhlt",
+		parse: parseAccountId,
+		valid: true,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:user%40example.com",
+		parse: parseAccountId,
+		valid: true,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:user%4",
+		parse: parseAccountId,
+		valid: false,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:user%zzexample",
+		parse: parseAccountId,
+		valid: false,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:user@example.com",
+		parse: parseAccountId,
+		valid: false,
+	}, {
+		id:    "chainstd:8c3444cf8970a9e41a706fab93e7a6c4:",
+		parse: parseAccountId,
+		valid: false,
+	}, {
+		id:    "eip155:1/erc1155:0x28959Cf125ccB051E70711D0924a62FB28EAF186/0",
+		parse: parseAssetId,
+		valid: true,
+	}, {
+		id:    "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769",
+		parse: parseAssetId,
+		valid: true,
+	}, {
+		id:    "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769/1",
+		parse: parseAssetId,
+		valid: false,
+	}, {
+		id:    "eip155:1/ERC20:0x6b175474e89094c44da98b954eedeac495271d0f",
+		parse: parseAssetId,
+		valid: false,
+	}, {
+		id:    "eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f!",
+		parse: parseAssetId,
+		valid: false,
+	}, {
+		id:    "eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08!",
+		parse: parseTransactionId,
+		valid: false,
+	}} {
+		err := tc.parse(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed non-conformant id %s", tc.id)
+		}
+	}
+}
+
+func TestLenientScan(t *testing.T) {
+	blockchain.SetLenientScan(true)
+	t.Cleanup(func() { blockchain.SetLenientScan(false) })
+
+	var c blockchain.ChainId
+	if err := c.Scan("eip155!!:1"); err != nil {
+		t.Errorf("Failed to scan legacy chain id: %v", err)
+	}
+
+	// Namespace validators still apply to legacy rows
+	if err := c.Scan("eip155:01"); err == nil {
+		t.Errorf("Lenient scan skipped the eip155 reference validator")
+	}
+
+	if _, err := blockchain.ParseChainId("eip155!!:1"); err == nil {
+		t.Errorf("Lenient scan relaxed parsing")
+	}
+
+	var a blockchain.AccountId
+	if err := a.Scan("polkadot:91b171bb158e2d3848fa23a9f1c25182:5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY!"); err != nil {
+		t.Errorf("Failed to scan legacy account id: %v", err)
+	}
+
+	// Legacy rows are still returned in canonical form
+	if err := a.Scan("eip155:1:0xAB16A96D359EC26A11E2C2B3D8F8B8942D5BFCDB"); err != nil {
+		t.Errorf("Failed to scan legacy account id: %v", err)
+	}
+	if a.Address != "0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb" {
+		t.Errorf("Lenient scan did not canonicalise %s", a.Address)
+	}
+
+	var as blockchain.AssetId
+	if err := as.Scan("eip155:1/token:0x6b175474e89094c44da98b954eedeac495271d0f!"); err != nil {
+		t.Errorf("Failed to scan legacy asset id: %v", err)
+	}
+
+	if err := as.Scan("eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f!"); err == nil {
+		t.Errorf("Lenient scan skipped the erc20 reference validator")
+	}
+
+	var tt blockchain.TransactionId
+	if err := tt.Scan("polkadot:91b171bb158e2d3848fa23a9f1c25182:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08!"); err != nil {
+		t.Errorf("Failed to scan legacy transaction id: %v", err)
+	}
+
+	if err := tt.Scan("eip155:1:0x66F2462A072D837B5C4A76DE103A7E5D1CD42C5F77FBD4F95A0DCC9FDDF90B08"); err != nil {
+		t.Errorf("Failed to scan legacy transaction id: %v", err)
+	}
+	if tt.Hash != "0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08" {
+		t.Errorf("Lenient scan did not canonicalise %s", tt.Hash)
+	}
+
+	// Deposit memos are still validated
+	var d blockchain.DepositAddress
+	if err := d.Scan("xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:007"); err != nil {
+		t.Errorf("Failed to scan legacy deposit address: %v", err)
+	}
+	if d.Memo.Value != "7" {
+		t.Errorf("Lenient scan did not canonicalise memo %s", d.Memo.Value)
+	}
+
+	if err := d.Scan("xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#text:12345"); err == nil {
+		t.Errorf("Lenient scan skipped the xrpl memo normaliser")
+	}
+}
+
+// gqlId is an id that is written to and read from GraphQL
+type gqlId interface {
+	fmt.Stringer
+	MarshalGQL(w io.Writer)
+}
+
+// Ids written with MarshalGQL are uppercase but must be read back unchanged
+func TestGQLRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		id      gqlId
+		decoded interface {
+			fmt.Stringer
+			UnmarshalGQL(v interface{}) error
+		}
+	}{
+		{blockchain.MustParseChainId("eip155:1"), &blockchain.ChainId{}},
+		{blockchain.MustParseChainId("bip122:000000000019d6689c085ae165831e93"), &blockchain.ChainId{}},
+		{blockchain.MustParseAccountId("eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb"), &blockchain.AccountId{}},
+		{blockchain.MustParseAccountId("cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0"), &blockchain.AccountId{}},
+		{blockchain.MustParseAssetId("eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d/771769"), &blockchain.AssetId{}},
+		{blockchain.MustParseAssetId("cosmos:cosmoshub-4/slip44:118"), &blockchain.AssetId{}},
+		{blockchain.MustParseTransactionId("eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08"), &blockchain.TransactionId{}},
+		{blockchain.MustParseBlockId("eip155:1:19000000"), &blockchain.BlockId{}},
+		{blockchain.MustParseBlockId("bip122:000000000019d6689c085ae165831e93:0:000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"), &blockchain.BlockId{}},
+		{blockchain.MustParseEventId("eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08:3"), &blockchain.EventId{}},
+	} {
+		var gql bytes.Buffer
+		tc.id.MarshalGQL(&gql)
+
+		unquoted, err := strconv.Unquote(gql.String())
+		if err != nil {
+			t.Fatalf("Failed to unquote gql: %v", err)
+		}
+
+		if err := tc.decoded.UnmarshalGQL(unquoted); err != nil {
+			t.Errorf("Failed to unmarshal gql id %s: %v", unquoted, err)
+		} else if tc.decoded.String() != tc.id.String() {
+			t.Errorf("Round trip through gql changed %s to %s", tc.id, tc.decoded)
+		}
+	}
+}
+
+func parseChainId(s string) error {
+	_, err := blockchain.ParseChainId(s)
+	return err
+}
+
+func parseAccountId(s string) error {
+	_, err := blockchain.ParseAccountId(s)
+	return err
+}
+
+func parseAssetId(s string) error {
+	_, err := blockchain.ParseAssetId(s)
+	return err
+}
+
+func parseTransactionId(s string) error {
+	_, err := blockchain.ParseTransactionId(s)
+	return err
+}
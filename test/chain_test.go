@@ -2,6 +2,8 @@ package test
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/offblocks/offblocks-common/blockchain"
@@ -106,3 +108,91 @@ func TestChainId(t *testing.T) {
 		}
 	}
 }
+
+func TestChainIdNamespaceValidation(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		valid bool
+	}{{
+		id:    "eip155:137",
+		valid: true,
+	}, {
+		id:    "eip155:abc",
+		valid: false,
+	}, {
+		id:    "eip155:0",
+		valid: false,
+	}, {
+		id:    "bip122:000000000933ea01ad0ee984209779ba",
+		valid: true,
+	}, {
+		id:    "bip122:1",
+		valid: false,
+	}, {
+		id:    "bip122:000000000019D6689C085AE165831E93",
+		valid: false,
+	}, {
+		id:    "cosmos:osmosis-1",
+		valid: true,
+	}, {
+		id:    "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+		valid: true,
+	}, {
+		id:    "solana:0OIl",
+		valid: false,
+	}, {
+		id:    "polkadot:91b171bb158e2d3848fa23a9f1c25182",
+		valid: true,
+	}, {
+		id:    "polkadot:b0a8d493285c2df7",
+		valid: false,
+	}} {
+		_, err := blockchain.ParseChainId(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse chain id %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed invalid chain id %s", tc.id)
+		}
+
+		var c blockchain.ChainId
+		err = json.Unmarshal([]byte(`"`+tc.id+`"`), &c)
+		if tc.valid != (err == nil) {
+			t.Errorf("Unexpected json validation result for %s: %v", tc.id, err)
+		}
+
+		err = c.Scan(tc.id)
+		if tc.valid != (err == nil) {
+			t.Errorf("Unexpected sql validation result for %s: %v", tc.id, err)
+		}
+
+		// GraphQL ids are case insensitive, as MarshalGQL writes them in uppercase
+		_, lowerErr := blockchain.ParseChainId(strings.ToLower(tc.id))
+		err = c.UnmarshalGQL(tc.id)
+		if (tc.valid || lowerErr == nil) != (err == nil) {
+			t.Errorf("Unexpected gql validation result for %s: %v", tc.id, err)
+		}
+	}
+}
+
+func TestRegisterChainNamespace(t *testing.T) {
+	if _, err := blockchain.NewChainId("testns", "anything"); err != nil {
+		t.Fatalf("Failed to create chain id in unregistered namespace: %v", err)
+	}
+
+	blockchain.RegisterChainNamespace("testns", func(reference string) error {
+		if reference != "main" {
+			return errors.New("unknown testns reference")
+		}
+		return nil
+	})
+	t.Cleanup(func() { blockchain.UnregisterChainNamespace("testns") })
+
+	if _, err := blockchain.NewChainId("testns", "main"); err != nil {
+		t.Errorf("Failed to create chain id in registered namespace: %v", err)
+	}
+
+	if _, err := blockchain.NewChainId("testns", "anything"); err == nil {
+		t.Errorf("Created chain id rejected by registered validator")
+	}
+}
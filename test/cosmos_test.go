@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+func TestCosmosAccountId(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		valid bool
+	}{{
+		id:    "cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0",
+		valid: true,
+	}, {
+		id:    "cosmos:osmosis-1:osmo1t2uflqwqe0fsj0shcfkrvpukewcw40yj6pyawa",
+		valid: true,
+	}, {
+		// Prefix does not match chain
+		id:    "cosmos:osmosis-1:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0",
+		valid: false,
+	}, {
+		// Invalid checksum
+		id:    "cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc1",
+		valid: false,
+	}, {
+		// Unknown chains accept any valid Bech32 prefix
+		id:    "cosmos:example-1:osmo1t2uflqwqe0fsj0shcfkrvpukewcw40yj6pyawa",
+		valid: true,
+	}} {
+		_, err := blockchain.ParseAccountId(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse account id %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed invalid account id %s", tc.id)
+		}
+	}
+}
+
+func TestConvertCosmosAccount(t *testing.T) {
+	hub := blockchain.MustParseAccountId("cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0")
+	osmosis := blockchain.MustParseChainId("cosmos:osmosis-1")
+
+	converted, err := blockchain.ConvertCosmosAccount(hub, osmosis)
+	if err != nil {
+		t.Fatalf("Failed to convert account: %v", err)
+	}
+
+	if converted.String() != "cosmos:osmosis-1:osmo1t2uflqwqe0fsj0shcfkrvpukewcw40yj6pyawa" {
+		t.Errorf("Unexpected converted account %s", converted)
+	}
+
+	back, err := blockchain.ConvertCosmosAccount(converted, hub.ChainId)
+	if err != nil {
+		t.Fatalf("Failed to convert account back: %v", err)
+	}
+
+	if !back.Equal(hub) {
+		t.Errorf("Round trip conversion changed account: %s", back)
+	}
+
+	if _, err := blockchain.ConvertCosmosAccount(hub, blockchain.MustParseChainId("cosmos:example-1")); err == nil {
+		t.Errorf("Converted account to chain with unknown prefix")
+	}
+
+	blockchain.RegisterCosmosPrefix("example-1", "example")
+	t.Cleanup(func() { blockchain.UnregisterCosmosChain("example-1") })
+	if _, err := blockchain.ConvertCosmosAccount(hub, blockchain.MustParseChainId("cosmos:example-1")); err != nil {
+		t.Errorf("Failed to convert account to registered chain: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositAddress(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want string
+	}{{
+		s:    "xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:12345",
+		want: "xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:12345",
+	}, {
+		// Ids are normalised without leading zeros
+		s:    "xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:007",
+		want: "xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:7",
+	}, {
+		s:    "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#text:customer #42",
+		want: "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#text:customer #42",
+	}, {
+		s:    "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#id:18446744073709551615",
+		want: "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#id:18446744073709551615",
+	}, {
+		s:    "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#hash:" + strings.Repeat("AB", 32),
+		want: "stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#hash:" + strings.Repeat("ab", 32),
+	}, {
+		s:    "cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0#text:104577383",
+		want: "cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0#text:104577383",
+	}, {
+		s:    "ton:-239:UQBvW8Z5huBkMJYdnfAEM5JqTNkuWX3diqYENkWsIL0XggGG#text:\"quoted\" \\ comment",
+		want: "ton:-239:UQBvW8Z5huBkMJYdnfAEM5JqTNkuWX3diqYENkWsIL0XggGG#text:\"quoted\" \\ comment",
+	}, {
+		// Addresses without a memo are plain account ids
+		s:    "eip155:1:0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae",
+		want: "eip155:1:0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae",
+	}} {
+		d, err := blockchain.ParseDepositAddress(tc.s)
+		require.NoError(t, err, tc.s)
+		require.Equal(t, tc.want, d.String())
+
+		data, err := json.Marshal(d)
+		require.NoError(t, err)
+		var unmarshalled blockchain.DepositAddress
+		require.NoError(t, json.Unmarshal(data, &unmarshalled))
+		require.Equal(t, d, unmarshalled)
+
+		value, err := d.Value()
+		require.NoError(t, err)
+		var scanned blockchain.DepositAddress
+		require.NoError(t, scanned.Scan(value))
+		require.Equal(t, d, scanned)
+
+		pb, err := d.MarshalProto()
+		require.NoError(t, err)
+		var fromProto blockchain.DepositAddress
+		require.NoError(t, fromProto.UnmarshalProto(pb))
+		require.Equal(t, d, fromProto)
+	}
+
+	for _, invalid := range []string{
+		// Destination tags are 32 bit
+		"xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:4294967296",
+		"xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#text:12345",
+		"xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:",
+		"xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#12345",
+		// Stellar text memos are at most 28 bytes
+		"stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#text:" + strings.Repeat("x", 29),
+		"stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#hash:abcd",
+		"stellar:pubnet:GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7#return:abcd",
+		"cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0#id:1",
+		// Cosmos text memos are at most 256 bytes, not characters
+		"cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0#text:" + strings.Repeat("é", 129),
+		"ton:-239:UQBvW8Z5huBkMJYdnfAEM5JqTNkuWX3diqYENkWsIL0XggGG#text:" + strings.Repeat("x", 124),
+		// Chains without shared deposit addresses do not take memos
+		"eip155:1:0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae#text:1",
+	} {
+		_, err := blockchain.ParseDepositAddress(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestNewDepositAddress(t *testing.T) {
+	account := blockchain.MustParseAccountId("xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+
+	d, err := blockchain.NewDepositAddress(account, &blockchain.Memo{Type: blockchain.MemoId, Value: "0042"})
+	require.NoError(t, err)
+	require.Equal(t, "42", d.Memo.Value)
+	require.True(t, d.Equal(blockchain.MustParseDepositAddress("xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:42")))
+
+	// Deposits to the same address with a different memo belong to a different
+	// customer
+	require.False(t, d.Equal(blockchain.MustParseDepositAddress("xrpl:0:rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh#id:43")))
+	require.False(t, d.Equal(blockchain.DepositAddress{AccountId: account}))
+
+	_, err = blockchain.NewDepositAddress(account, &blockchain.Memo{Type: blockchain.MemoText, Value: "42"})
+	require.Error(t, err)
+
+	// Namespaces can register their own memo rules
+	blockchain.RegisterMemoNamespace("algorand", func(_ blockchain.ChainId, memo blockchain.Memo) (blockchain.Memo, error) {
+		return memo, nil
+	})
+	t.Cleanup(func() { blockchain.UnregisterMemoNamespace("algorand") })
+	_, err = blockchain.ParseDepositAddress("algorand:wGHE2Pwdvd7S12BL5FaOP20EGYesN73k:HZ57J3K46JIJXILONBBZOHX6BKPXEM2VVXNRFSUED6DKFD5ZD24PMJ3MVA#text:note")
+	require.NoError(t, err)
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivationPath(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want blockchain.DerivationPath
+	}{{
+		path: "m",
+		want: blockchain.DerivationPath{},
+	}, {
+		path: "m/44'/60'/0'/0/5",
+		want: blockchain.DerivationPath{44 + blockchain.HardenedKeyStart, 60 + blockchain.HardenedKeyStart, blockchain.HardenedKeyStart, 0, 5},
+	}, {
+		path: "m/0/2147483647'/1/2147483646'/2",
+		want: blockchain.DerivationPath{0, 0xffffffff, 1, 0xfffffffe, 2},
+	}} {
+		p, err := blockchain.ParseDerivationPath(tc.path)
+		require.NoError(t, err, tc.path)
+		require.Equal(t, tc.want, p)
+		require.Equal(t, tc.path, p.String())
+
+		data, err := json.Marshal(p)
+		require.NoError(t, err)
+		var unmarshalled blockchain.DerivationPath
+		require.NoError(t, json.Unmarshal(data, &unmarshalled))
+		require.Equal(t, p, unmarshalled)
+
+		value, err := p.Value()
+		require.NoError(t, err)
+		var scanned blockchain.DerivationPath
+		require.NoError(t, scanned.Scan(value))
+		require.Equal(t, p, scanned)
+	}
+
+	// Alternative hardened markers are accepted and serialised with '
+	p, err := blockchain.ParseDerivationPath("m/84h/0H/0h/1/3")
+	require.NoError(t, err)
+	require.Equal(t, "m/84'/0'/0'/1/3", p.String())
+
+	coinType, ok := p.CoinType()
+	require.True(t, ok)
+	require.Equal(t, uint32(0), coinType)
+
+	for _, invalid := range []string{
+		"",
+		"44'/60'",
+		"m/",
+		"m/a",
+		"m/-1",
+		"m/01",
+		"m/0''",
+		"m/2147483648",
+	} {
+		_, err := blockchain.ParseDerivationPath(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestNewBIP44Path(t *testing.T) {
+	for _, tc := range []struct {
+		chainId string
+		path    string
+	}{{
+		chainId: "eip155:1",
+		path:    "m/44'/60'/0'/0/5",
+	}, {
+		// EVM testnets use the coin type of ether
+		chainId: "eip155:11155111",
+		path:    "m/44'/60'/0'/0/5",
+	}, {
+		chainId: "eip155:137",
+		path:    "m/44'/60'/0'/0/5",
+	}, {
+		chainId: "eip155:17000",
+		path:    "m/44'/60'/0'/0/5",
+	}, {
+		chainId: "bip122:000000000933ea01ad0ee984209779ba",
+		path:    "m/44'/1'/0'/0/5",
+	}, {
+		chainId: "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+		path:    "m/44'/501'/0'/0/5",
+	}, {
+		chainId: "tron:0x2b6653dc",
+		path:    "m/44'/195'/0'/0/5",
+	}} {
+		p, err := blockchain.NewBIP44Path(blockchain.MustParseChainId(tc.chainId), 0, 0, 5)
+		require.NoError(t, err, tc.chainId)
+		require.Equal(t, tc.path, p.String())
+	}
+
+	_, err := blockchain.NewBIP44Path(blockchain.MustParseChainId("eip155:1"), blockchain.HardenedKeyStart, 0, 0)
+	require.Error(t, err)
+}
+
+func TestExtendedPublicKey(t *testing.T) {
+	// BIP-32 test vector 1, m/0'
+	key, err := blockchain.ParseExtendedPublicKey("xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw")
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), key.Depth)
+	require.Equal(t, "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", key.String())
+
+	// m/0'/1
+	child, err := key.Child(1)
+	require.NoError(t, err)
+	require.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", child.String())
+
+	_, err = child.Child(2 + blockchain.HardenedKeyStart)
+	require.Error(t, err)
+
+	// m/0'/1/2'/2 to m/0'/1/2'/2/1000000000
+	key, err = blockchain.ParseExtendedPublicKey("xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV")
+	require.NoError(t, err)
+	child, err = key.Derive(blockchain.DerivationPath{1000000000})
+	require.NoError(t, err)
+	require.Equal(t, "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", child.String())
+
+	// Deposit addresses derived from the same path are stable
+	path := blockchain.MustParseDerivationPath("m/0/5")
+	account, err := key.DeriveAccountId(blockchain.MustParseChainId("eip155:1"), path)
+	require.NoError(t, err)
+	derived, err := key.Derive(path)
+	require.NoError(t, err)
+	expected, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("eip155:1"), derived.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, expected, account)
+
+	// The version selects the bitcoin script type and must match the network
+	bitcoin := blockchain.MustParseChainId("bip122:000000000019d6689c085ae165831e93")
+	bitcoinTestnet := blockchain.MustParseChainId("bip122:000000000933ea01ad0ee984209779ba")
+	for _, tc := range []struct {
+		version    uint32
+		chainId    blockchain.ChainId
+		scriptType blockchain.BitcoinScriptType
+	}{
+		{0x0488b21e, bitcoin, blockchain.BitcoinP2PKH},
+		{0x049d7cb2, bitcoin, blockchain.BitcoinP2SH},
+		{0x04b24746, bitcoin, blockchain.BitcoinP2WPKH},
+		{0x043587cf, bitcoinTestnet, blockchain.BitcoinP2PKH},
+		{0x044a5262, bitcoinTestnet, blockchain.BitcoinP2SH},
+		{0x045f1cf6, bitcoinTestnet, blockchain.BitcoinP2WPKH},
+	} {
+		versioned := key
+		versioned.Version = tc.version
+
+		account, err := versioned.DeriveAccountId(tc.chainId, path)
+		require.NoError(t, err)
+		expected, err := blockchain.NewBitcoinAccountIdFromPublicKey(tc.chainId, derived.PublicKey, tc.scriptType)
+		require.NoError(t, err)
+		require.Equal(t, expected, account)
+	}
+
+	_, err = key.DeriveAccountId(bitcoinTestnet, path)
+	require.Error(t, err)
+
+	zpub := key
+	zpub.Version = 0x04b24746
+	_, err = zpub.DeriveAccountId(blockchain.MustParseChainId("eip155:1"), path)
+	require.Error(t, err)
+
+	unknown := key
+	unknown.Version = 0x12345678
+	_, err = unknown.DeriveAccountId(bitcoin, path)
+	require.Error(t, err)
+
+	// Extended private keys are rejected
+	_, err = blockchain.ParseExtendedPublicKey("xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi")
+	require.Error(t, err)
+
+	_, err = blockchain.ParseExtendedPublicKey("not an xpub")
+	require.Error(t, err)
+}
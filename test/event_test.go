@@ -0,0 +1,114 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+func TestEventId(t *testing.T) {
+	for _, tc := range []struct {
+		id string
+	}{{
+		// Ethereum mainnet log
+		id: "eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08:3",
+	}, {
+		// Solana mainnet instruction
+		id: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:3QorZbZ5bRWePAqRsAW5vMLggKfaJQ7RTdKEoXQBsWtgunjrQAN8wrj99yLDqAQassU7DzVYdB62rygzKQd7m7fU:0",
+	}} {
+		e := blockchain.EventId{}
+		if err := e.Parse(tc.id); err != nil {
+			t.Errorf("Failed to parse event id: %v", err)
+		}
+
+		if e.String() != tc.id {
+			t.Errorf("Failed to serialize event id to string")
+		}
+
+		if _, err := blockchain.NewEventId(e.TransactionId, e.Index); err != nil {
+			t.Errorf("Failed to create event id from transaction id and index")
+		}
+
+		b, err := e.MarshalText()
+		if err != nil {
+			t.Errorf("Failed to marshal to text")
+		}
+
+		e = blockchain.EventId{}
+		if err := e.UnmarshalText(b); err != nil {
+			t.Errorf("Failed to unmarshal from text")
+		}
+
+		if e.String() != tc.id {
+			t.Errorf("Unmarshalled event id invalid")
+		}
+
+		b, err = json.Marshal(e)
+		if err != nil {
+			t.Errorf("Failed to marshal to json")
+		}
+
+		e = blockchain.EventId{}
+		if err := json.Unmarshal(b, &e); err != nil {
+			t.Errorf("Failed to unmarshal to json")
+		}
+
+		pb, err := e.MarshalProto()
+		if err != nil {
+			t.Errorf("Failed to marshal to proto")
+		}
+
+		e = blockchain.EventId{}
+		if err := e.UnmarshalProto(pb); err != nil {
+			t.Errorf("Failed to unmarshal from proto")
+		}
+
+		if e.String() != tc.id {
+			t.Errorf("Unmarshalled event id invalid")
+		}
+
+		e2 := blockchain.EventId{}
+		if err := e2.Scan(e.String()); err != nil {
+			t.Errorf("Scanning value from sql.NullString")
+		}
+
+		if e2 != e {
+			t.Errorf("Scanned value not valid")
+		}
+	}
+
+	// Hashes are normalised for the chain namespace when created and written
+	literal := blockchain.TransactionId{
+		ChainId: blockchain.MustParseChainId("eip155:1"),
+		Hash:    "0x66F2462A072D837B5C4A76DE103A7E5D1CD42C5F77FBD4F95A0DCC9FDDF90B08",
+	}
+	e, err := blockchain.NewEventId(literal, 3)
+	if err != nil {
+		t.Fatalf("Failed to create event id: %v", err)
+	}
+	if e.String() != "eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08:3" {
+		t.Errorf("Created event id not canonical: %s", e)
+	}
+
+	v, err := blockchain.EventId{TransactionId: literal, Index: 3}.Value()
+	if err != nil || v != e.String() {
+		t.Errorf("Event id value not canonical: %v", v)
+	}
+
+	literal.Hash = "abc"
+	if _, err := blockchain.NewEventId(literal, 3); err == nil {
+		t.Errorf("Created event id with invalid hash")
+	}
+
+	for _, id := range []string{
+		"eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08",
+		"eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08:",
+		"eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08:-1",
+		"eip155:1:3",
+	} {
+		if _, err := blockchain.ParseEventId(id); err == nil {
+			t.Errorf("Parsed invalid event id %s", id)
+		}
+	}
+}
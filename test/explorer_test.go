@@ -0,0 +1,99 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplorerResolver(t *testing.T) {
+	resolver := blockchain.NewExplorerResolver(nil)
+
+	for _, tc := range []struct {
+		resolve func() (string, error)
+		url     string
+	}{{
+		resolve: func() (string, error) {
+			u, err := resolver.ChainURL(blockchain.MustParseChainId("eip155:1"))
+			return u.String(), err
+		},
+		url: "https://etherscan.io",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.AccountURL(blockchain.MustParseAccountId("eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb"))
+			return u.String(), err
+		},
+		url: "https://etherscan.io/address/0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.AssetURL(blockchain.MustParseAssetId("eip155:137/erc20:0x3c499c542cef5e3811e1192ce70d8cc03d5c3359"))
+			return u.String(), err
+		},
+		url: "https://polygonscan.com/token/0x3c499c542cef5e3811e1192ce70d8cc03d5c3359",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.AssetURL(blockchain.MustParseAssetId("eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769"))
+			return u.String(), err
+		},
+		url: "https://etherscan.io/nft/0x06012c8cf97bead5deae237070f9587f8e7a266d/771769",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.TransactionURL(blockchain.MustParseTransactionId("bip122:000000000019d6689c085ae165831e93:c55e6d98f3867f5bffdd3fae24082ba56a50e81e13c46b67716343a1fedda9ba"))
+			return u.String(), err
+		},
+		url: "https://mempool.space/tx/c55e6d98f3867f5bffdd3fae24082ba56a50e81e13c46b67716343a1fedda9ba",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.AccountURL(blockchain.MustParseAccountId("solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1:7S3P4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArAaKv"))
+			return u.String(), err
+		},
+		url: "https://solscan.io/account/7S3P4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArAaKv?cluster=devnet",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.AccountURL(blockchain.MustParseAccountId("tron:0x2b6653dc:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))
+			return u.String(), err
+		},
+		url: "https://tronscan.org/#/address/TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+	}, {
+		resolve: func() (string, error) {
+			u, err := resolver.TransactionURL(blockchain.MustParseTransactionId("cosmos:cosmoshub-4:A57352B805703E81164196D050D9DBAC3283304518A421CD0BC4767C143E02ED"))
+			return u.String(), err
+		},
+		url: "https://www.mintscan.io/cosmos/tx/A57352B805703E81164196D050D9DBAC3283304518A421CD0BC4767C143E02ED",
+	}} {
+		u, err := tc.resolve()
+		require.NoError(t, err)
+		require.Equal(t, tc.url, u)
+	}
+
+	// Native assets and fiat currencies are not token contracts
+	for _, id := range []string{
+		"bip122:000000000019d6689c085ae165831e93/slip44:0",
+		"eip155:1/slip44:60",
+		"fiat:0/iso4217:USD",
+	} {
+		_, err := resolver.AssetURL(blockchain.MustParseAssetId(id))
+		require.True(t, errors.Is(err, errors.ErrUnsupported), id)
+	}
+
+	_, err := resolver.AccountURL(blockchain.MustParseAccountId("eip155:424242:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb"))
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
+
+func TestExplorerResolverOverrides(t *testing.T) {
+	ethereum := blockchain.MustParseChainId("eip155:1")
+	resolver := blockchain.NewExplorerResolver(map[blockchain.ChainId]blockchain.ExplorerTemplates{
+		ethereum: {
+			Transaction: "https://explorer.internal/eth/tx/{hash}",
+		},
+	})
+
+	u, err := resolver.TransactionURL(blockchain.MustParseTransactionId("eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08"))
+	require.NoError(t, err)
+	require.Equal(t, "https://explorer.internal/eth/tx/0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08", u.String())
+
+	_, err = resolver.AccountURL(blockchain.MustParseAccountId("eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb"))
+	require.Error(t, err)
+}
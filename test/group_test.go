@@ -0,0 +1,99 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEquivalentAssets(t *testing.T) {
+	usdcBase := blockchain.MustParseAssetId("eip155:8453/erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913")
+	usdcSolana := blockchain.MustParseAssetId("solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	// Bridged USDC.e on Polygon
+	usdcBridged := blockchain.MustParseAssetId("eip155:137/erc20:0x2791bca1f2de4661ed88a30c99a7a9449aa84174")
+	// Checksummed form of the Ethereum USDC contract
+	usdcChecksummed := blockchain.MustParseAssetId("eip155:1/erc20:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	for _, asset := range []blockchain.AssetId{usdc, usdcBase, usdcSolana, usdcBridged, usdcChecksummed} {
+		key, ok := blockchain.AssetGroupKey(asset)
+		require.True(t, ok, asset.String())
+		require.Equal(t, "usdc", key)
+	}
+
+	equivalent := blockchain.EquivalentAssets(usdcBase)
+	require.Contains(t, equivalent, usdc)
+	require.Contains(t, equivalent, usdcSolana)
+	require.Contains(t, equivalent, usdcBridged)
+
+	require.True(t, usdcBase.Equivalent(usdcSolana))
+	require.True(t, usdc.Equivalent(usdcChecksummed))
+	require.False(t, usdc.Equivalent(ether))
+
+	ungrouped := blockchain.MustParseAssetId("eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f")
+	_, ok := blockchain.AssetGroupKey(ungrouped)
+	require.False(t, ok)
+	require.Equal(t, []blockchain.AssetId{ungrouped}, blockchain.EquivalentAssets(ungrouped))
+	require.True(t, ungrouped.Equivalent(ungrouped))
+}
+
+func TestRegisterAssetGroup(t *testing.T) {
+	dai := blockchain.MustParseAssetId("eip155:1/erc20:0x6b175474e89094c44da98b954eedeac495271d0f")
+	daiOptimism := blockchain.MustParseAssetId("eip155:10/erc20:0xda10009cbd5d07dd0cecc66161fc93d7c9000da1")
+	t.Cleanup(func() {
+		for _, key := range []string{"test-dai", "test-moved", "test-loaded"} {
+			blockchain.UnregisterAssetGroup(key)
+		}
+	})
+
+	require.NoError(t, blockchain.RegisterAssetGroup(blockchain.AssetGroup{
+		Key:    "test-dai",
+		Name:   "Dai",
+		Assets: []blockchain.AssetId{dai, daiOptimism},
+	}))
+	require.True(t, dai.Equivalent(daiOptimism))
+
+	group, ok := blockchain.LookupAssetGroup("test-dai")
+	require.True(t, ok)
+	require.Len(t, group.Assets, 2)
+
+	// Replacing a group removes the assets that are no longer in it
+	require.NoError(t, blockchain.RegisterAssetGroup(blockchain.AssetGroup{
+		Key:    "test-dai",
+		Assets: []blockchain.AssetId{dai},
+	}))
+	require.False(t, dai.Equivalent(daiOptimism))
+
+	// Assets are moved out of the group they previously belonged to
+	require.NoError(t, blockchain.RegisterAssetGroup(blockchain.AssetGroup{
+		Key:    "test-moved",
+		Assets: []blockchain.AssetId{dai, daiOptimism},
+	}))
+	group, ok = blockchain.LookupAssetGroup("test-dai")
+	require.True(t, ok)
+	require.Empty(t, group.Assets)
+
+	key, ok := blockchain.AssetGroupKey(dai)
+	require.True(t, ok)
+	require.Equal(t, "test-moved", key)
+
+	require.Error(t, blockchain.RegisterAssetGroup(blockchain.AssetGroup{Key: "Invalid Key"}))
+	require.Error(t, blockchain.RegisterAssetGroup(blockchain.AssetGroup{
+		Key:    "test-nft",
+		Assets: []blockchain.AssetId{blockchain.MustParseAssetId("eip155:1/erc721:0x06012c8cf97bead5deae237070f9587f8e7a266d/771769")},
+	}))
+
+	require.NoError(t, blockchain.LoadAssetGroups(strings.NewReader(`[{"key":"test-loaded","assets":["eip155:100/slip44:700"]}]`)))
+	key, ok = blockchain.AssetGroupKey(blockchain.MustParseAssetId("eip155:100/slip44:700"))
+	require.True(t, ok)
+	require.Equal(t, "test-loaded", key)
+
+	require.Error(t, blockchain.LoadAssetGroups(strings.NewReader(`[{"key":"test-invalid","assets":["not an asset"]}]`)))
+
+	blockchain.UnregisterAssetGroup("test-moved")
+	_, ok = blockchain.LookupAssetGroup("test-moved")
+	require.False(t, ok)
+	_, ok = blockchain.AssetGroupKey(dai)
+	require.False(t, ok)
+}
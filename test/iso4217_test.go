@@ -0,0 +1,114 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiatAsset(t *testing.T) {
+	for _, tc := range []struct {
+		code       string
+		id         string
+		numeric    uint16
+		minorUnits int32
+	}{{
+		code:       "USD",
+		id:         "fiat:0/iso4217:USD",
+		numeric:    840,
+		minorUnits: 2,
+	}, {
+		code:       "JPY",
+		id:         "fiat:0/iso4217:JPY",
+		numeric:    392,
+		minorUnits: 0,
+	}, {
+		code:       "ISK",
+		id:         "fiat:0/iso4217:ISK",
+		numeric:    352,
+		minorUnits: 0,
+	}, {
+		code:       "KWD",
+		id:         "fiat:0/iso4217:KWD",
+		numeric:    414,
+		minorUnits: 3,
+	}} {
+		asset, err := blockchain.NewFiatAsset(tc.code)
+		require.NoError(t, err)
+		require.Equal(t, tc.id, asset.String())
+		require.True(t, asset.IsFiat())
+
+		parsed, err := blockchain.ParseAssetId(tc.id)
+		require.NoError(t, err)
+		require.Equal(t, asset, parsed)
+
+		currency, err := parsed.Currency()
+		require.NoError(t, err)
+		require.Equal(t, tc.numeric, currency.Numeric)
+		require.Equal(t, tc.minorUnits, currency.MinorUnits)
+		require.Equal(t, asset, currency.AssetId())
+	}
+
+	for _, invalid := range []string{
+		// Unknown currency
+		"fiat:0/iso4217:XYZ",
+		// Lowercase currency code
+		"fiat:0/iso4217:usd",
+		// Fiat currencies are only legal on the fiat chain
+		"eip155:1/iso4217:USD",
+		// Unknown fiat chain reference
+		"fiat:1/iso4217:USD",
+	} {
+		_, err := blockchain.ParseAssetId(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	_, err := blockchain.MustParseAssetId("eip155:1/slip44:60").Currency()
+	require.Error(t, err)
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	_, err := blockchain.NewFiatAsset("XTS")
+	require.Error(t, err)
+
+	require.NoError(t, blockchain.RegisterCurrency(blockchain.Currency{Code: "XTS", Numeric: 963, MinorUnits: 2, Name: "Testing Code"}))
+	t.Cleanup(func() { blockchain.UnregisterCurrency("XTS") })
+
+	asset, err := blockchain.NewFiatAsset("XTS")
+	require.NoError(t, err)
+	require.Equal(t, "fiat:0/iso4217:XTS", asset.String())
+
+	require.Error(t, blockchain.RegisterCurrency(blockchain.Currency{Code: "xts"}))
+	require.Error(t, blockchain.RegisterCurrency(blockchain.Currency{Code: "XTT", MinorUnits: -1}))
+	_, ok := blockchain.LookupCurrency("XTT")
+	require.False(t, ok)
+
+	currencies := blockchain.Currencies()
+	require.GreaterOrEqual(t, len(currencies), 150)
+	for i := 1; i < len(currencies); i++ {
+		require.Less(t, currencies[i-1].Code, currencies[i].Code)
+	}
+}
+
+func TestFiatAmount(t *testing.T) {
+	a, err := blockchain.NewFiatAmountFromMinorUnits("USD", big.NewInt(12345))
+	require.NoError(t, err)
+	require.Equal(t, "123.45 fiat:0/iso4217:USD", a.String())
+
+	cents, err := a.MinorUnits()
+	require.NoError(t, err)
+	require.Equal(t, int64(12345), cents.Int64())
+
+	yen, err := blockchain.ParseAmount("1.5 fiat:0/iso4217:JPY")
+	require.NoError(t, err)
+	_, err = yen.MinorUnits()
+	require.Error(t, err)
+
+	_, err = blockchain.NewFiatAmountFromMinorUnits("XYZ", big.NewInt(1))
+	require.Error(t, err)
+
+	_, err = amount(ether, "1").MinorUnits()
+	require.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testTokenList = `{
+  "name": "Test List",
+  "timestamp": "2024-01-01T00:00:00.000Z",
+  "version": {"major": 1, "minor": 0, "patch": 0},
+  "tokens": [
+    {
+      "chainId": 1,
+      "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+      "name": "USD Coin",
+      "symbol": "USDC",
+      "decimals": 6,
+      "logoURI": "https://example.com/usdc.png"
+    },
+    {
+      "chainId": 8453,
+      "address": "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+      "name": "USD Coin",
+      "symbol": "USDC",
+      "decimals": 6
+    }
+  ]
+}`
+
+func TestMemoryAssetMetadataProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := blockchain.NewMemoryAssetMetadataProvider(map[blockchain.AssetId]blockchain.AssetMetadata{
+		ether: {Symbol: "ETH", Name: "Ether", Decimals: 18},
+	})
+
+	metadata, err := provider.AssetMetadata(ctx, ether)
+	require.NoError(t, err)
+	require.Equal(t, "ETH", metadata.Symbol)
+
+	_, err = provider.AssetMetadata(ctx, usdc)
+	require.True(t, errors.Is(err, commonerrors.ErrNotFound))
+
+	provider.Set(usdc, blockchain.AssetMetadata{Symbol: "USDC", Name: "USD Coin", Decimals: 6})
+	metadata, err = provider.AssetMetadata(ctx, usdc)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), metadata.Decimals)
+
+	formatted, err := blockchain.FormatAmount(ctx, provider, amount(usdc, "1.5"))
+	require.NoError(t, err)
+	require.Equal(t, "1.500000 USDC", formatted)
+}
+
+func TestTokenListAssetMetadataProvider(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(testTokenList), 0o600))
+
+	provider, err := blockchain.NewFileAssetMetadataProvider(path)
+	require.NoError(t, err)
+
+	// Lookups match regardless of the case of the contract address
+	metadata, err := provider.AssetMetadata(ctx, usdc)
+	require.NoError(t, err)
+	require.Equal(t, blockchain.AssetMetadata{
+		Symbol:   "USDC",
+		Name:     "USD Coin",
+		Decimals: 6,
+		Logo:     types.MustParse("https://example.com/usdc.png"),
+	}, metadata)
+
+	metadata, err = provider.AssetMetadata(ctx, blockchain.MustParseAssetId("eip155:8453/erc20:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913"))
+	require.NoError(t, err)
+	require.Equal(t, "USDC", metadata.Symbol)
+
+	_, err = provider.AssetMetadata(ctx, ether)
+	require.True(t, errors.Is(err, commonerrors.ErrNotFound))
+
+	_, err = blockchain.NewTokenListAssetMetadataProvider(strings.NewReader(`{"tokens":[{"chainId":1,"address":"not an address"}]}`))
+	require.Error(t, err)
+
+	_, err = blockchain.NewFileAssetMetadataProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+type countingAssetMetadataProvider struct {
+	provider blockchain.AssetMetadataProvider
+	calls    int
+	err      error
+}
+
+func (p *countingAssetMetadataProvider) AssetMetadata(ctx context.Context, asset blockchain.AssetId) (blockchain.AssetMetadata, error) {
+	p.calls++
+	if p.err != nil {
+		return blockchain.AssetMetadata{}, p.err
+	}
+
+	return p.provider.AssetMetadata(ctx, asset)
+}
+
+func TestCachingAssetMetadataProvider(t *testing.T) {
+	ctx := context.Background()
+	counting := &countingAssetMetadataProvider{
+		provider: blockchain.NewMemoryAssetMetadataProvider(map[blockchain.AssetId]blockchain.AssetMetadata{
+			ether: {Symbol: "ETH", Name: "Ether", Decimals: 18},
+		}),
+	}
+	provider := blockchain.NewCachingAssetMetadataProvider(counting, 0)
+
+	for i := 0; i < 3; i++ {
+		metadata, err := provider.AssetMetadata(ctx, ether)
+		require.NoError(t, err)
+		require.Equal(t, "ETH", metadata.Symbol)
+
+		_, err = provider.AssetMetadata(ctx, usdc)
+		require.True(t, errors.Is(err, commonerrors.ErrNotFound))
+	}
+	require.Equal(t, 2, counting.calls)
+
+	// Transient errors are not cached
+	failing := &countingAssetMetadataProvider{err: errors.New("unavailable")}
+	provider = blockchain.NewCachingAssetMetadataProvider(failing, time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := provider.AssetMetadata(ctx, ether)
+		require.Error(t, err)
+	}
+	require.Equal(t, 2, failing.calls)
+
+	// Entries expire after the time to live
+	counting.calls = 0
+	provider = blockchain.NewCachingAssetMetadataProvider(counting, time.Millisecond)
+	_, err := provider.AssetMetadata(ctx, ether)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = provider.AssetMetadata(ctx, ether)
+	require.NoError(t, err)
+	require.Equal(t, 2, counting.calls)
+}
@@ -0,0 +1,196 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/payment"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentURIRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	provider := blockchain.NewMemoryAssetMetadataProvider(map[blockchain.AssetId]blockchain.AssetMetadata{
+		blockchain.MustParseAssetId("eip155:1/slip44:60"):                                          {Symbol: "ETH", Decimals: 18},
+		blockchain.MustParseAssetId("eip155:137/slip44:966"):                                       {Symbol: "POL", Decimals: 18},
+		blockchain.MustParseAssetId("eip155:137/erc20:0x3c499c542cef5e3811e1192ce70d8cc03d5c3359"): {Symbol: "USDC", Decimals: 6},
+	})
+
+	for _, tc := range []struct {
+		uri       string
+		recipient string
+		asset     string
+		amount    string
+		label     string
+		message   string
+	}{{
+		// EIP-681 native transfer on mainnet
+		uri:       "ethereum:0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359?value=2014000000000000000",
+		recipient: "eip155:1:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		asset:     "eip155:1/slip44:60",
+		amount:    "2.014",
+	}, {
+		// EIP-681 native transfer on Polygon without an amount
+		uri:       "ethereum:0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359@137",
+		recipient: "eip155:137:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		asset:     "eip155:137/slip44:966",
+	}, {
+		// EIP-681 native transfers on chains with unknown native assets are
+		// in ether
+		uri:       "ethereum:0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359@56",
+		recipient: "eip155:56:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		asset:     "eip155:56/slip44:60",
+	}, {
+		uri:       "ethereum:0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359@17000?value=1500000000000000000",
+		recipient: "eip155:17000:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		asset:     "eip155:17000/slip44:60",
+		amount:    "1.5",
+	}, {
+		// EIP-681 ERC-20 transfer on Polygon
+		uri:       "ethereum:0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359@137/transfer?address=0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359&uint256=1500000",
+		recipient: "eip155:137:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		asset:     "eip155:137/erc20:0x3c499c542cef5e3811e1192ce70d8cc03d5c3359",
+		amount:    "1.5",
+	}, {
+		// BIP-21 with the parameters of the specification example
+		uri:       "bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6?amount=50&label=Luke-Jr&message=Donation%20for%20project%20xyz",
+		recipient: "bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		asset:     "bip122:000000000019d6689c085ae165831e93/slip44:0",
+		amount:    "50",
+		label:     "Luke-Jr",
+		message:   "Donation for project xyz",
+	}, {
+		// BIP-21 testnet segwit address
+		uri:       "bitcoin:tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx?amount=0.00001",
+		recipient: "bip122:000000000933ea01ad0ee984209779ba:tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		asset:     "bip122:000000000933ea01ad0ee984209779ba/slip44:1",
+		amount:    "0.00001",
+	}, {
+		// Solana Pay SOL transfer
+		uri:       "solana:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN?amount=1&label=Michael&message=Thanks%20for%20all%20the%20fish",
+		recipient: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN",
+		asset:     "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/slip44:501",
+		amount:    "1",
+		label:     "Michael",
+		message:   "Thanks for all the fish",
+	}, {
+		// Solana Pay SPL token transfer
+		uri:       "solana:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN?amount=0.01&spl-token=EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		recipient: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN",
+		asset:     "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/spl:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		amount:    "0.01",
+	}} {
+		r, err := payment.Parse(ctx, tc.uri, provider)
+		require.NoError(t, err, tc.uri)
+		require.Equal(t, tc.recipient, r.Recipient.String(), tc.uri)
+		require.Equal(t, tc.asset, r.Asset.String(), tc.uri)
+		require.Equal(t, tc.label, r.Label, tc.uri)
+		require.Equal(t, tc.message, r.Message, tc.uri)
+
+		if tc.amount == "" {
+			require.Nil(t, r.Amount, tc.uri)
+		} else {
+			require.NotNil(t, r.Amount, tc.uri)
+			require.True(t, r.Amount.Equal(decimal.RequireFromString(tc.amount)), tc.uri)
+		}
+
+		uri, err := payment.Format(ctx, r, provider)
+		require.NoError(t, err, tc.uri)
+		require.Equal(t, tc.uri, uri)
+	}
+}
+
+func TestPaymentURIParse(t *testing.T) {
+	ctx := context.Background()
+
+	// EIP-681 scientific notation, pay- prefix and native decimals from the
+	// default provider
+	r, err := payment.Parse(ctx, "ethereum:pay-0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359?value=2.014e18", nil)
+	require.NoError(t, err)
+	require.Equal(t, "2.014", r.Amount.String())
+
+	// Solana Pay references and memo
+	r, err = payment.Parse(ctx, "solana:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN?reference=82ZJ7nbGpixjeDCmEhUcmwXYfvurzAgGdtSMuHnUgyny&memo=OrderId12345", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"82ZJ7nbGpixjeDCmEhUcmwXYfvurzAgGdtSMuHnUgyny"}, r.References)
+	require.Equal(t, "OrderId12345", r.Memo)
+
+	uri, err := payment.Format(ctx, r, nil)
+	require.NoError(t, err)
+	require.Equal(t, "solana:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN?reference=82ZJ7nbGpixjeDCmEhUcmwXYfvurzAgGdtSMuHnUgyny&memo=OrderId12345", uri)
+
+	for _, invalid := range []string{
+		"",
+		"dogecoin:DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L",
+		// Invalid checksum
+		"bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p7",
+		// Unknown required parameter
+		"bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6?req-somethingyoudontunderstand=50",
+		// Negative and scientific amounts
+		"bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6?amount=-1",
+		// Amounts smaller than a satoshi
+		"bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6?amount=0.000000001",
+		"solana:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN?amount=1e3",
+		// Fractional base units
+		"ethereum:0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359?value=1.5",
+		// ENS names are not resolved
+		"ethereum:vitalik.eth?value=1",
+		// Unsupported function
+		"ethereum:0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359@137/approve?address=0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		// ERC-20 decimals unknown to the default provider
+		"ethereum:0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359@137/transfer?address=0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359&uint256=1",
+		// Solana Pay transaction request
+		"solana:https%3A%2F%2Fexample.com%2Fsolana-pay",
+	} {
+		_, err := payment.Parse(ctx, invalid, nil)
+		require.Error(t, err, invalid)
+	}
+
+	// BIP-21 unknown optional parameters are ignored
+	_, err = payment.Parse(ctx, "bitcoin:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6?somethingyoudontunderstand=50", nil)
+	require.NoError(t, err)
+}
+
+func TestPaymentURIFormat(t *testing.T) {
+	ctx := context.Background()
+	recipient := blockchain.MustParseAccountId("eip155:1:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+
+	// Amounts more precise than the asset's base unit cannot be encoded
+	amount := types.Decimal{Decimal: decimal.RequireFromString("0.0000000000000000001")}
+	_, err := payment.Format(ctx, payment.Request{Recipient: recipient, Amount: &amount}, nil)
+	require.Error(t, err)
+
+	bitcoin := blockchain.MustParseAccountId("bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6")
+	_, err = payment.Format(ctx, payment.Request{Recipient: bitcoin, Amount: &amount}, nil)
+	require.Error(t, err)
+
+	negative := types.Decimal{Decimal: decimal.RequireFromString("-1")}
+	_, err = payment.Format(ctx, payment.Request{Recipient: recipient, Amount: &negative}, nil)
+	require.Error(t, err)
+
+	// Assets must be on the recipient's chain
+	_, err = payment.Format(ctx, payment.Request{Recipient: recipient, Asset: blockchain.MustParseAssetId("eip155:137/slip44:966")}, nil)
+	require.Error(t, err)
+
+	_, err = payment.Format(ctx, payment.Request{Recipient: blockchain.MustParseAccountId("cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0")}, nil)
+	require.Error(t, err)
+
+	// Requests that would be parsed as on another chain cannot be encoded
+	for _, recipient := range []string{
+		// Testnet4 and signet addresses are parsed as testnet3
+		"bip122:00000000da84f2bafbbc53dee25a72ae:tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		"bip122:00000008819873e925422c1ff0f99f7c:tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		// Legacy regtest addresses share the testnet prefix
+		"bip122:0f9188f13cb7b2c71f2a335e3a4fc328:mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn",
+		// Solana Pay urls do not identify the cluster
+		"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1:mvines9iiHiQTysrwkJjGf2gb9Ex9jXJX8ns3qwf2kN",
+	} {
+		_, err := payment.Format(ctx, payment.Request{Recipient: blockchain.MustParseAccountId(recipient)}, nil)
+		require.True(t, errors.Is(err, commonerrors.ErrUnsupported), recipient)
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// generatorKey is the compressed public key of private key 1, the secp256k1
+// generator point
+var generatorKey = mustDecodeHex("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+
+func TestNewAccountIdFromPublicKey(t *testing.T) {
+	uncompressed := secp256k1.PrivKeyFromBytes([]byte{1}).PubKey().SerializeUncompressed()
+
+	for _, tc := range []struct {
+		chainId   string
+		publicKey []byte
+		id        string
+	}{{
+		chainId:   "eip155:1",
+		publicKey: generatorKey,
+		id:        "eip155:1:0x7e5f4552091a69125d5dfcb7b8c2659029395bdf",
+	}, {
+		// Uncompressed keys derive the same address
+		chainId:   "eip155:1",
+		publicKey: uncompressed,
+		id:        "eip155:1:0x7e5f4552091a69125d5dfcb7b8c2659029395bdf",
+	}, {
+		// Bitcoin accounts default to P2WPKH
+		chainId:   "bip122:000000000019d6689c085ae165831e93",
+		publicKey: generatorKey,
+		id:        "bip122:000000000019d6689c085ae165831e93:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+	}, {
+		chainId:   "bip122:000000000933ea01ad0ee984209779ba",
+		publicKey: generatorKey,
+		id:        "bip122:000000000933ea01ad0ee984209779ba:tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+	}, {
+		// System program
+		chainId:   "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+		publicKey: make([]byte, 32),
+		id:        "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:11111111111111111111111111111111",
+	}} {
+		account, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId(tc.chainId), tc.publicKey)
+		require.NoError(t, err, tc.id)
+		require.Equal(t, tc.id, account.String())
+	}
+
+	// Cosmos addresses share the key hash of the P2WPKH address and differ only
+	// in their prefix between chains
+	hub, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("cosmos:cosmoshub-4"), generatorKey)
+	require.NoError(t, err)
+	require.Regexp(t, "^cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k", hub.Address)
+
+	osmosis, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("cosmos:osmosis-1"), generatorKey)
+	require.NoError(t, err)
+	converted, err := blockchain.ConvertCosmosAccount(hub, osmosis.ChainId)
+	require.NoError(t, err)
+	require.Equal(t, osmosis, converted)
+
+	// Injective uses eth_secp256k1 keys, so its addresses share the key hash of
+	// the eip155 address
+	injective, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("cosmos:injective-1"), generatorKey)
+	require.NoError(t, err)
+	require.Equal(t, "inj10e0525sfrf53yh2aljmm3sn9jq5njk7lwfmzjf", injective.Address)
+
+	evmos, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("cosmos:hashed-95094315a9d6cf2a"), generatorKey)
+	require.NoError(t, err)
+	converted, err = blockchain.ConvertCosmosAccount(injective, evmos.ChainId)
+	require.NoError(t, err)
+	require.Equal(t, evmos, converted)
+
+	// Accounts cannot be converted between chains with different key types
+	_, err = blockchain.ConvertCosmosAccount(hub, injective.ChainId)
+	require.True(t, errors.Is(err, commonerrors.ErrUnsupported))
+
+	// Tron addresses share the key hash of the eip155 address
+	tron, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId("tron:0x2b6653dc"), generatorKey)
+	require.NoError(t, err)
+	tronHex, err := blockchain.TronBase58ToHex(tron.Address)
+	require.NoError(t, err)
+	require.Equal(t, "417e5f4552091a69125d5dfcb7b8c2659029395bdf", tronHex)
+
+	for _, tc := range []struct {
+		chainId   string
+		publicKey []byte
+	}{{
+		chainId:   "eip155:1",
+		publicKey: generatorKey[:32],
+	}, {
+		chainId:   "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+		publicKey: generatorKey,
+	}, {
+		// Unknown cosmos prefix
+		chainId:   "cosmos:unknown-1",
+		publicKey: generatorKey,
+	}, {
+		// Unknown bitcoin network
+		chainId:   "bip122:00000000000000000000000000000000",
+		publicKey: generatorKey,
+	}, {
+		chainId:   "polkadot:91b171bb158e2d3848fa23a9f1c25182",
+		publicKey: bytes.Repeat([]byte{1}, 32),
+	}} {
+		_, err := blockchain.NewAccountIdFromPublicKey(blockchain.MustParseChainId(tc.chainId), tc.publicKey)
+		require.Error(t, err, tc.chainId)
+	}
+}
+
+func TestNewBitcoinAccountIdFromPublicKey(t *testing.T) {
+	mainnet := blockchain.MustParseChainId("bip122:000000000019d6689c085ae165831e93")
+
+	for _, tc := range []struct {
+		publicKey  []byte
+		scriptType blockchain.BitcoinScriptType
+		address    string
+	}{{
+		publicKey:  generatorKey,
+		scriptType: blockchain.BitcoinP2PKH,
+		address:    "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+	}, {
+		publicKey:  generatorKey,
+		scriptType: blockchain.BitcoinP2WPKH,
+		address:    "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+	}, {
+		// BIP-86 test vector for m/86'/0'/0'/0/0
+		publicKey:  mustDecodeHex("03cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115"),
+		scriptType: blockchain.BitcoinP2TR,
+		address:    "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+	}} {
+		account, err := blockchain.NewBitcoinAccountIdFromPublicKey(mainnet, tc.publicKey, tc.scriptType)
+		require.NoError(t, err, tc.address)
+		require.Equal(t, tc.address, account.Address)
+
+		decoded, err := account.BitcoinAddress()
+		require.NoError(t, err)
+		require.Equal(t, tc.scriptType, decoded.ScriptType)
+	}
+
+	// BIP-49 test vector, m/49'/1'/0'/0/0
+	testnet := blockchain.MustParseChainId("bip122:000000000933ea01ad0ee984209779ba")
+	account, err := blockchain.NewBitcoinAccountIdFromPublicKey(testnet, mustDecodeHex("03a1af804ac108a8a51782198c2d034b28bf90c8803f5a53f76276fa69a4eae77f"), blockchain.BitcoinP2SH)
+	require.NoError(t, err)
+	require.Equal(t, "2Mww8dCYPUpKHofjgcXcBCEGmniw9CoaiD2", account.Address)
+
+	_, err = blockchain.NewBitcoinAccountIdFromPublicKey(mainnet, generatorKey, blockchain.BitcoinP2WSH)
+	require.Error(t, err)
+
+	_, err = blockchain.NewBitcoinAccountIdFromPublicKey(blockchain.MustParseChainId("eip155:1"), generatorKey, blockchain.BitcoinP2WPKH)
+	require.Error(t, err)
+}
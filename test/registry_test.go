@@ -0,0 +1,167 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupChain(t *testing.T) {
+	for _, tc := range []struct {
+		id          string
+		name        string
+		nativeAsset string
+		decimals    int32
+		testnet     bool
+	}{{
+		id:          "eip155:1",
+		name:        "Ethereum",
+		nativeAsset: "eip155:1/slip44:60",
+		decimals:    18,
+	}, {
+		id:          "eip155:137",
+		name:        "Polygon",
+		nativeAsset: "eip155:137/slip44:966",
+		decimals:    18,
+	}, {
+		id:          "bip122:000000000019d6689c085ae165831e93",
+		name:        "Bitcoin",
+		nativeAsset: "bip122:000000000019d6689c085ae165831e93/slip44:0",
+		decimals:    8,
+	}, {
+		id:          "bip122:000000000933ea01ad0ee984209779ba",
+		name:        "Bitcoin Testnet",
+		nativeAsset: "bip122:000000000933ea01ad0ee984209779ba/slip44:1",
+		decimals:    8,
+		testnet:     true,
+	}, {
+		id:          "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+		name:        "Solana",
+		nativeAsset: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/slip44:501",
+		decimals:    9,
+	}, {
+		id:          "cosmos:cosmoshub-4",
+		name:        "Cosmos Hub",
+		nativeAsset: "cosmos:cosmoshub-4/slip44:118",
+		decimals:    6,
+	}} {
+		info, ok := blockchain.MustParseChainId(tc.id).Info()
+		require.True(t, ok, "chain %s not registered", tc.id)
+		require.Equal(t, tc.name, info.Name)
+		require.Equal(t, tc.nativeAsset, info.NativeAsset.String())
+		require.Equal(t, tc.decimals, info.NativeDecimals)
+		require.Equal(t, tc.testnet, info.Testnet)
+		require.NotZero(t, info.BlockTime)
+		require.NotZero(t, info.FinalityDepth)
+	}
+
+	_, ok := blockchain.LookupChain(blockchain.MustParseChainId("eip155:999999"))
+	require.False(t, ok)
+}
+
+func TestLoadChains(t *testing.T) {
+	t.Cleanup(func() { blockchain.UnregisterChain(blockchain.MustParseChainId("eip155:999999")) })
+
+	err := blockchain.LoadChains(strings.NewReader(`[{
+		"chainId": "eip155:999999",
+		"name": "Example",
+		"nativeAsset": "eip155:999999/slip44:60",
+		"nativeDecimals": 18,
+		"testnet": true,
+		"blockTime": "1.5s",
+		"finalityDepth": 10
+	}]`))
+	require.NoError(t, err)
+
+	info, ok := blockchain.LookupChain(blockchain.MustParseChainId("eip155:999999"))
+	require.True(t, ok)
+	require.Equal(t, "Example", info.Name)
+	require.Equal(t, 1500*time.Millisecond, info.BlockTime)
+
+	err = blockchain.LoadChains(strings.NewReader(`[{
+		"chainId": "eip155:999999",
+		"name": "Example Renamed",
+		"nativeAsset": "eip155:999999/slip44:60",
+		"nativeDecimals": 18,
+		"blockTime": "2s",
+		"finalityDepth": 32
+	}]`))
+	require.NoError(t, err)
+
+	info, ok = blockchain.LookupChain(blockchain.MustParseChainId("eip155:999999"))
+	require.True(t, ok)
+	require.Equal(t, "Example Renamed", info.Name)
+	require.Equal(t, uint64(32), info.FinalityDepth)
+
+	b, err := json.Marshal(info)
+	require.NoError(t, err)
+
+	var decoded blockchain.ChainInfo
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, info, decoded)
+
+	err = blockchain.LoadChains(strings.NewReader(`[{"chainId": "eip155:5", "nativeAsset": "eip155:1/slip44:60"}]`))
+	require.Error(t, err)
+
+	err = blockchain.LoadChains(strings.NewReader(`[{"chainId": "eip155:abc"}]`))
+	require.Error(t, err)
+
+	blockchain.UnregisterChain(info.ChainId)
+	_, ok = blockchain.LookupChain(info.ChainId)
+	require.False(t, ok)
+}
+
+func TestParseChainAlias(t *testing.T) {
+	for _, tc := range []struct {
+		alias string
+		id    string
+		name  string
+	}{{
+		alias: "ethereum",
+		id:    "eip155:1",
+		name:  "Ethereum",
+	}, {
+		alias: "eth-sepolia",
+		id:    "eip155:11155111",
+		name:  "Ethereum Sepolia",
+	}, {
+		alias: "Polygon",
+		id:    "eip155:137",
+		name:  "Polygon",
+	}, {
+		alias: "bitcoin-testnet",
+		id:    "bip122:000000000933ea01ad0ee984209779ba",
+		name:  "Bitcoin Testnet",
+	}, {
+		alias: "eip155:8453",
+		id:    "eip155:8453",
+		name:  "Base",
+	}, {
+		alias: "eip155:424242",
+		id:    "eip155:424242",
+		name:  "eip155:424242",
+	}} {
+		chainId, err := blockchain.ParseChainAlias(tc.alias)
+		require.NoError(t, err)
+		require.Equal(t, tc.id, chainId.String())
+		require.Equal(t, tc.name, chainId.DisplayName())
+	}
+
+	_, err := blockchain.ParseChainAlias("not-a-chain")
+	require.Error(t, err)
+
+	blockchain.RegisterChainAlias("local", blockchain.MustParseChainId("eip155:31337"))
+	t.Cleanup(func() { blockchain.UnregisterChainAlias("local") })
+
+	chainId, err := blockchain.ParseChainAlias("local")
+	require.NoError(t, err)
+	require.Equal(t, "eip155:31337", chainId.String())
+
+	blockchain.UnregisterChainAlias("local")
+	_, err = blockchain.ParseChainAlias("local")
+	require.Error(t, err)
+}
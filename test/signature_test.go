@@ -0,0 +1,273 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/offblocks/offblocks-common/auth"
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// cowKey is the private key keccak256("cow") used by the EIP-712 specification
+var cowKey = secp256k1.PrivKeyFromBytes(mustDecodeHex("c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4"))
+
+var cowAccount = blockchain.MustParseAccountId("eip155:1:0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+
+// signEIP155 signs a hash returning the 65 byte r || s || v form with v of 27
+// or 28
+func signEIP155(key *secp256k1.PrivateKey, hash []byte) []byte {
+	compact := ecdsa.SignCompact(key, hash, false)
+	return append(compact[1:], compact[0])
+}
+
+func TestVerifyPersonalSignature(t *testing.T) {
+	message := []byte("Link wallet to OffBlocks")
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte("\x19Ethereum Signed Message:\n24"))
+	h.Write(message)
+	signature := signEIP155(cowKey, h.Sum(nil))
+
+	require.NoError(t, auth.VerifyPersonalSignature(cowAccount, message, signature))
+	require.NoError(t, auth.VerifyMessageSignature(cowAccount, message, signature))
+
+	// Recovery ids of 0 and 1 are also accepted
+	zeroBased := append([]byte{}, signature...)
+	zeroBased[64] -= 27
+	require.NoError(t, auth.VerifyPersonalSignature(cowAccount, message, zeroBased))
+
+	// The signature proves ownership of the address on any eip155 chain
+	require.NoError(t, auth.VerifyPersonalSignature(blockchain.MustParseAccountId("eip155:137:0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826"), message, signature))
+
+	other := blockchain.MustParseAccountId("eip155:1:0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	err := auth.VerifyPersonalSignature(other, message, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	err = auth.VerifyPersonalSignature(cowAccount, []byte("Link wallet to OffBlockz"), signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	err = auth.VerifyPersonalSignature(cowAccount, message, signature[:64])
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+}
+
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestVerifyTypedDataSignature(t *testing.T) {
+	var data auth.TypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &data))
+
+	// EIP-712 specification example
+	hash, err := data.Hash()
+	require.NoError(t, err)
+	require.Equal(t, mustDecodeHex("be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"), hash)
+
+	signature := append(mustDecodeHex("4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"), 28)
+	require.NoError(t, auth.VerifyTypedDataSignature(cowAccount, data, signature))
+
+	// The domain binds the signature to chain 1
+	err = auth.VerifyTypedDataSignature(blockchain.MustParseAccountId("eip155:137:0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), data, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	data.Message["contents"] = "Hello, Alice!"
+	err = auth.VerifyTypedDataSignature(cowAccount, data, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+}
+
+func TestTypedDataHashInvalid(t *testing.T) {
+	for name, mutate := range map[string]func(d *auth.TypedData){
+		"missing domain type":  func(d *auth.TypedData) { delete(d.Types, "EIP712Domain") },
+		"missing string field": func(d *auth.TypedData) { delete(d.Message, "contents") },
+		"unknown type":         func(d *auth.TypedData) { d.PrimaryType = "Letter" },
+		"invalid address": func(d *auth.TypedData) {
+			d.Message["to"] = map[string]any{"name": "Bob", "wallet": "0x1234"}
+		},
+		"uint out of range": func(d *auth.TypedData) { d.Domain["chainId"] = "-1" },
+	} {
+		var data auth.TypedData
+		require.NoError(t, json.Unmarshal([]byte(mailTypedData), &data))
+		mutate(&data)
+
+		_, err := data.Hash()
+		require.True(t, errors.Is(err, commonerrors.ErrInvalid), name)
+	}
+
+	// Missing and null struct fields are encoded as zero, as MetaMask does
+	var data auth.TypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &data))
+	full, err := data.Hash()
+	require.NoError(t, err)
+
+	delete(data.Message, "to")
+	missing, err := data.Hash()
+	require.NoError(t, err)
+	require.NotEqual(t, full, missing)
+
+	data.Message["to"] = nil
+	null, err := data.Hash()
+	require.NoError(t, err)
+	require.Equal(t, missing, null)
+
+	// Arrays and integers of other widths are encoded
+	data = auth.TypedData{
+		Types: map[string][]auth.TypedDataField{
+			"EIP712Domain": {{Name: "name", Type: "string"}},
+			"Batch": {
+				{Name: "ids", Type: "uint32[]"},
+				{Name: "delta", Type: "int8"},
+				{Name: "tag", Type: "bytes4"},
+			},
+		},
+		PrimaryType: "Batch",
+		Domain:      map[string]any{"name": "Batch"},
+		Message:     map[string]any{"ids": []any{1.0, "0x02"}, "delta": -1.0, "tag": "0xdeadbeef"},
+	}
+	_, err = data.Hash()
+	require.NoError(t, err)
+
+	data.Message["delta"] = 128.0
+	_, err = data.Hash()
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+}
+
+func TestVerifySolanaSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	account, err := blockchain.NewAccountId(blockchain.MustParseChainId("solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"), base58.Encode(publicKey))
+	require.NoError(t, err)
+
+	message := []byte("Link wallet to OffBlocks")
+	signature := ed25519.Sign(privateKey, message)
+	require.NoError(t, auth.VerifySolanaSignature(account, message, signature))
+	require.NoError(t, auth.VerifyMessageSignature(account, message, signature))
+
+	err = auth.VerifySolanaSignature(account, []byte("Link wallet to OffBlockz"), signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	err = auth.VerifySolanaSignature(cowAccount, message, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnsupported))
+}
+
+func TestVerifyBIP322Signature(t *testing.T) {
+	// BIP-322 test vectors, signed by the private key of
+	// L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k
+	p2wpkh := blockchain.MustParseAccountId("bip122:000000000019d6689c085ae165831e93:bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l")
+	p2tr := blockchain.MustParseAccountId("bip122:000000000019d6689c085ae165831e93:bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3")
+
+	for _, tc := range []struct {
+		account   blockchain.AccountId
+		message   string
+		signature string
+	}{{
+		account:   p2wpkh,
+		message:   "",
+		signature: "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+	}, {
+		account:   p2wpkh,
+		message:   "Hello World",
+		signature: "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+	}, {
+		account:   p2tr,
+		message:   "Hello World",
+		signature: "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==",
+	}} {
+		signature, err := base64.StdEncoding.DecodeString(tc.signature)
+		require.NoError(t, err)
+
+		require.NoError(t, auth.VerifyBIP322Signature(tc.account, []byte(tc.message), signature), tc.account)
+		require.NoError(t, auth.VerifyMessageSignature(tc.account, []byte(tc.message), signature), tc.account)
+
+		err = auth.VerifyBIP322Signature(tc.account, []byte("Hello World!"), signature)
+		require.True(t, errors.Is(err, commonerrors.ErrUnauthorised), tc.account)
+	}
+
+	// A valid signature by a different address
+	signature, err := base64.StdEncoding.DecodeString("AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=")
+	require.NoError(t, err)
+	other := blockchain.MustParseAccountId("bip122:000000000019d6689c085ae165831e93:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	err = auth.VerifyBIP322Signature(other, []byte("Hello World"), signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	err = auth.VerifyBIP322Signature(p2wpkh, []byte("Hello World"), signature[:20])
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+}
+
+func doubleSHA256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func TestVerifyLegacyBitcoinSignature(t *testing.T) {
+	key := secp256k1.PrivKeyFromBytes([]byte{1})
+	mainnet := blockchain.MustParseChainId("bip122:000000000019d6689c085ae165831e93")
+	message := []byte("Link wallet to OffBlocks")
+
+	// signmessage signs the double SHA-256 of the prefixed message
+	prefixed := append([]byte("\x18Bitcoin Signed Message:\n"), byte(len(message)))
+	hash := doubleSHA256(append(prefixed, message...))
+	signature := ecdsa.SignCompact(key, hash, true)
+
+	p2pkh, err := blockchain.NewBitcoinAccountIdFromPublicKey(mainnet, generatorKey, blockchain.BitcoinP2PKH)
+	require.NoError(t, err)
+	require.NoError(t, auth.VerifyBIP322Signature(p2pkh, message, signature))
+
+	// BIP-137 marks P2WPKH signatures with a header offset by 8
+	p2wpkh, err := blockchain.NewBitcoinAccountIdFromPublicKey(mainnet, generatorKey, blockchain.BitcoinP2WPKH)
+	require.NoError(t, err)
+	segwit := append([]byte{signature[0] + 8}, signature[1:]...)
+	require.NoError(t, auth.VerifyBIP322Signature(p2wpkh, message, segwit))
+
+	other, err := blockchain.NewBitcoinAccountIdFromPublicKey(mainnet, cowKey.PubKey().SerializeCompressed(), blockchain.BitcoinP2PKH)
+	require.NoError(t, err)
+	err = auth.VerifyBIP322Signature(other, message, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	// Signatures for uncompressed keys do not match the compressed key address
+	uncompressed := append([]byte{signature[0] - 4}, signature[1:]...)
+	err = auth.VerifyBIP322Signature(p2pkh, message, uncompressed)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	err = auth.VerifyMessageSignature(blockchain.AccountId{ChainId: blockchain.MustParseChainId("cosmos:cosmoshub-4"), Address: "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k"}, message, signature)
+	require.True(t, errors.Is(err, commonerrors.ErrUnsupported))
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/offblocks/offblocks-common/auth"
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/offblocks/offblocks-common/internal/base58"
+	"github.com/offblocks/offblocks-common/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// EIP-4361 specification example
+const siweMessage = `service.org wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func personalSign(message string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message)) + message))
+	return signEIP155(cowKey, h.Sum(nil))
+}
+
+func TestParseSIWxMessage(t *testing.T) {
+	m, err := auth.ParseSIWxMessage(siweMessage)
+	require.NoError(t, err)
+	require.Equal(t, "service.org", m.Domain.Host)
+	require.Equal(t, "eip155:1:0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", m.AccountId.String())
+	require.Equal(t, "I accept the ServiceOrg Terms of Service: https://service.org/tos", m.Statement)
+	require.Equal(t, "https://service.org/login", m.URI.String())
+	require.Equal(t, "32891756", m.Nonce)
+	require.Equal(t, time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC), m.IssuedAt.Time)
+	require.Nil(t, m.ExpirationTime)
+	require.Len(t, m.Resources, 2)
+
+	// Addresses are rendered with their EIP-55 checksum
+	require.Equal(t, siweMessage, m.String())
+
+	for name, message := range map[string]string{
+		"empty":             "",
+		"unknown chain":     "service.org wants you to sign in with your Dogecoin account:\nD\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nNonce: 32891756\nIssued At: 2021-09-30T16:25:24Z",
+		"short nonce":       "service.org wants you to sign in with your Ethereum account:\n0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nNonce: 1234\nIssued At: 2021-09-30T16:25:24Z",
+		"missing nonce":     "service.org wants you to sign in with your Ethereum account:\n0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nIssued At: 2021-09-30T16:25:24Z\nRequest ID: 1",
+		"bad version":       "service.org wants you to sign in with your Ethereum account:\n0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\n\n\nURI: https://service.org\nVersion: 2\nChain ID: 1\nNonce: 32891756\nIssued At: 2021-09-30T16:25:24Z",
+		"lowercase address": "service.org wants you to sign in with your Ethereum account:\n0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nNonce: 32891756\nIssued At: 2021-09-30T16:25:24Z",
+		"cosmos account":    "service.org wants you to sign in with your Cosmos account:\ncosmos1w508d6qejxtdg4y5r3zarvary0c5xw7kyfmnzr\n\n\nURI: https://service.org\nVersion: 1\nChain ID: cosmoshub-4\nNonce: 32891756\nIssued At: 2021-09-30T16:25:24Z",
+		"bad address":       "service.org wants you to sign in with your Ethereum account:\n0xC02a\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nNonce: 32891756\nIssued At: 2021-09-30T16:25:24Z",
+		"bad time":          "service.org wants you to sign in with your Ethereum account:\n0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\n\n\nURI: https://service.org\nVersion: 1\nChain ID: 1\nNonce: 32891756\nIssued At: yesterday",
+		"trailing lines":    siweMessage + "\nSigned: yes",
+	} {
+		_, err := auth.ParseSIWxMessage(message)
+		require.Error(t, err, name)
+	}
+}
+
+func TestVerifySIWxMessage(t *testing.T) {
+	issuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expirationTime := types.Time{Time: issuedAt.Add(10 * time.Minute)}
+
+	nonce, err := auth.NewSIWxNonce()
+	require.NoError(t, err)
+
+	message, err := auth.SIWxMessage{
+		Domain:         types.MustParse("https://app.offblocks.xyz"),
+		AccountId:      cowAccount,
+		Statement:      "Sign in to OffBlocks",
+		URI:            types.MustParse("https://app.offblocks.xyz/login"),
+		Nonce:          nonce,
+		IssuedAt:       types.Time{Time: issuedAt},
+		ExpirationTime: &expirationTime,
+		RequestId:      "onboarding",
+	}.Format()
+	require.NoError(t, err)
+	signature := personalSign(message)
+
+	m, err := auth.VerifySIWxMessage(message, signature, types.MustParse("https://app.offblocks.xyz"), nonce, issuedAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, cowAccount, m.AccountId)
+	require.Equal(t, "onboarding", m.RequestId)
+
+	// The session carries the account that signed in
+	ctx := auth.WithAccountId(context.Background(), m.AccountId)
+	accountId, err := ctx.AccountId()
+	require.NoError(t, err)
+	require.Equal(t, cowAccount, accountId)
+
+	_, err = auth.Context{Context: context.Background()}.AccountId()
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+
+	// A domain without a scheme matches on host
+	_, err = auth.VerifySIWxMessage(message, signature, types.MustParse("app.offblocks.xyz"), nonce, issuedAt.Add(time.Minute))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		domain    string
+		nonce     string
+		now       time.Time
+		signature []byte
+	}{
+		"other domain":  {"https://evil.example", nonce, issuedAt.Add(time.Minute), signature},
+		"other scheme":  {"http://app.offblocks.xyz", nonce, issuedAt.Add(time.Minute), signature},
+		"other nonce":   {"https://app.offblocks.xyz", "0123456789", issuedAt.Add(time.Minute), signature},
+		"expired":       {"https://app.offblocks.xyz", nonce, expirationTime.Time, signature},
+		"other signer":  {"https://app.offblocks.xyz", nonce, issuedAt.Add(time.Minute), signEIP155(bolt11Other, make([]byte, 32))},
+		"bad signature": {"https://app.offblocks.xyz", nonce, issuedAt.Add(time.Minute), signature[:10]},
+	} {
+		_, err := auth.VerifySIWxMessage(message, tc.signature, types.MustParse(tc.domain), tc.nonce, tc.now)
+		require.True(t, errors.Is(err, commonerrors.ErrUnauthorised), name)
+	}
+}
+
+func TestVerifySIWxMessageSolana(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	account, err := blockchain.NewAccountId(blockchain.MustParseChainId("solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"), base58.Encode(publicKey))
+	require.NoError(t, err)
+
+	issuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	notBefore := types.Time{Time: issuedAt.Add(time.Minute)}
+	m := auth.SIWxMessage{
+		Domain:    types.MustParse("app.offblocks.xyz"),
+		AccountId: account,
+		URI:       types.MustParse("https://app.offblocks.xyz/login"),
+		Nonce:     "abcdef0123",
+		IssuedAt:  types.Time{Time: issuedAt},
+		NotBefore: &notBefore,
+	}
+	message, err := m.Format()
+	require.NoError(t, err)
+	require.Contains(t, message, "app.offblocks.xyz wants you to sign in with your Solana account:\n"+account.Address+"\n\n\nURI:")
+	require.Contains(t, message, "\nChain ID: 5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp\n")
+
+	parsed, err := auth.ParseSIWxMessage(message)
+	require.NoError(t, err)
+	require.Equal(t, message, parsed.String())
+
+	signature := ed25519.Sign(privateKey, []byte(message))
+	_, err = auth.VerifySIWxMessage(message, signature, types.MustParse("app.offblocks.xyz"), "abcdef0123", notBefore.Time)
+	require.NoError(t, err)
+
+	_, err = auth.VerifySIWxMessage(message, signature, types.MustParse("app.offblocks.xyz"), "abcdef0123", issuedAt)
+	require.True(t, errors.Is(err, commonerrors.ErrUnauthorised))
+}
+
+func TestFormatSIWxMessage(t *testing.T) {
+	m := auth.SIWxMessage{
+		Domain:    types.MustParse("app.offblocks.xyz"),
+		AccountId: blockchain.MustParseAccountId("tron:0x2b6653dc:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"),
+		URI:       types.MustParse("https://app.offblocks.xyz/login"),
+		Nonce:     "abcdef0123",
+		IssuedAt:  types.Time{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	// Namespaces without a sign-in chain name cannot be formatted
+	_, err := m.Format()
+	require.True(t, errors.Is(err, commonerrors.ErrUnsupported))
+
+	m.AccountId = cowAccount
+	m.Nonce = "1234"
+	_, err = m.Format()
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+
+	m.Nonce = "abcdef0123"
+	m.Statement = "Sign in\nto OffBlocks"
+	_, err = m.Format()
+	require.True(t, errors.Is(err, commonerrors.ErrInvalid))
+}
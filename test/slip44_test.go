@@ -0,0 +1,116 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+	commonerrors "github.com/offblocks/offblocks-common/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeAsset(t *testing.T) {
+	for _, tc := range []struct {
+		chainId string
+		asset   string
+	}{{
+		// Registered chains use their registered native asset
+		chainId: "eip155:137",
+		asset:   "eip155:137/slip44:966",
+	}, {
+		chainId: "bip122:000000000933ea01ad0ee984209779ba",
+		asset:   "bip122:000000000933ea01ad0ee984209779ba/slip44:1",
+	}, {
+		// Testnets use the testnet coin type
+		chainId: "eip155:11155111",
+		asset:   "eip155:11155111/slip44:1",
+	}, {
+		// Bitcoin signet uses the testnet coin type
+		chainId: "bip122:00000008819873e925422c1ff0f99f7c",
+		asset:   "bip122:00000008819873e925422c1ff0f99f7c/slip44:1",
+	}, {
+		// Litecoin
+		chainId: "bip122:12a765e31ffd4059bada1e25190f6e98",
+		asset:   "bip122:12a765e31ffd4059bada1e25190f6e98/slip44:2",
+	}, {
+		// Unregistered solana chains other than mainnet are testnets
+		chainId: "solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z",
+		asset:   "solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z/slip44:1",
+	}, {
+		chainId: "tron:0xcd8690dc",
+		asset:   "tron:0xcd8690dc/slip44:1",
+	}} {
+		asset, err := blockchain.NativeAsset(blockchain.MustParseChainId(tc.chainId))
+		require.NoError(t, err, tc.chainId)
+		require.Equal(t, tc.asset, asset.String())
+	}
+
+	for _, chainId := range []string{
+		"polkadot:b0a8d493285c2df73290dfb7e61f870f",
+		"bip122:00000000000000000000000000000000",
+		// The native assets of unregistered chains in namespaces whose chains
+		// have different native assets are not known
+		"eip155:17000",
+		"cosmos:juno-1",
+	} {
+		_, err := blockchain.NativeAsset(blockchain.MustParseChainId(chainId))
+		require.True(t, errors.Is(err, commonerrors.ErrNotFound), chainId)
+	}
+}
+
+func TestCoinType(t *testing.T) {
+	coinType, err := ether.CoinType()
+	require.NoError(t, err)
+	require.Equal(t, "ETH", coinType.Symbol)
+	require.Equal(t, int32(18), coinType.Decimals)
+
+	_, err = usdc.CoinType()
+	require.Error(t, err)
+
+	unknown := blockchain.MustParseAssetId("eip155:1/slip44:999999")
+	_, err = unknown.CoinType()
+	require.True(t, errors.Is(err, commonerrors.ErrNotFound))
+
+	blockchain.RegisterCoinType(blockchain.CoinType{CoinType: 999999, Symbol: "TST", Name: "Test", Decimals: 4})
+	t.Cleanup(func() { blockchain.UnregisterCoinType(999999) })
+	coinType, err = unknown.CoinType()
+	require.NoError(t, err)
+	require.Equal(t, "TST", coinType.Symbol)
+}
+
+func TestSLIP44AssetMetadataProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := blockchain.SLIP44AssetMetadataProvider{}
+
+	for _, tc := range []struct {
+		asset    string
+		symbol   string
+		decimals int32
+	}{{
+		asset:    "eip155:1/slip44:60",
+		symbol:   "ETH",
+		decimals: 18,
+	}, {
+		asset:    "bip122:000000000933ea01ad0ee984209779ba/slip44:1",
+		symbol:   "tBTC",
+		decimals: 8,
+	}, {
+		// Osmosis shares the cosmos coin type but has its own native token
+		asset:    "cosmos:osmosis-1/slip44:118",
+		symbol:   "OSMO",
+		decimals: 6,
+	}, {
+		asset:    "tron:0x2b6653dc/slip44:195",
+		symbol:   "TRX",
+		decimals: 6,
+	}} {
+		metadata, err := provider.AssetMetadata(ctx, blockchain.MustParseAssetId(tc.asset))
+		require.NoError(t, err, tc.asset)
+		require.Equal(t, tc.symbol, metadata.Symbol, tc.asset)
+		require.Equal(t, tc.decimals, metadata.Decimals, tc.asset)
+	}
+
+	_, err := provider.AssetMetadata(ctx, usdc)
+	require.True(t, errors.Is(err, commonerrors.ErrNotFound))
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/offblocks/offblocks-common/blockchain"
+)
+
+func TestSolanaAccountId(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		valid bool
+	}{{
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7S3P4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArAaKv",
+		valid: true,
+	}, {
+		// System program
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:11111111111111111111111111111111",
+		valid: true,
+	}, {
+		// Characters outside the Base58 alphabet
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:0OIl4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArAaKv",
+		valid: false,
+	}, {
+		// 31 bytes
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7S3P4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArA",
+		valid: false,
+	}} {
+		_, err := blockchain.ParseAccountId(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse account id %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed invalid account id %s", tc.id)
+		}
+	}
+}
+
+func TestTronAccountId(t *testing.T) {
+	for _, tc := range []struct {
+		id    string
+		valid bool
+	}{{
+		id:    "tron:0x2b6653dc:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		valid: true,
+	}, {
+		// Invalid checksum
+		id:    "tron:0x2b6653dc:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u",
+		valid: false,
+	}, {
+		// Bitcoin address with a valid checksum but the wrong prefix
+		id:    "tron:0x2b6653dc:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		valid: false,
+	}} {
+		_, err := blockchain.ParseAccountId(tc.id)
+		if tc.valid && err != nil {
+			t.Errorf("Failed to parse account id %s: %v", tc.id, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Parsed invalid account id %s", tc.id)
+		}
+	}
+}
+
+func TestTronAddressConversion(t *testing.T) {
+	hex, err := blockchain.TronBase58ToHex("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	if err != nil {
+		t.Fatalf("Failed to convert base58 address: %v", err)
+	}
+
+	if hex != "41a614f803b6fd780986a42c78ec9c7f77e6ded13c" {
+		t.Errorf("Unexpected hex address %s", hex)
+	}
+
+	for _, address := range []string{
+		"41a614f803b6fd780986a42c78ec9c7f77e6ded13c",
+		"0xa614f803b6fd780986a42c78ec9c7f77e6ded13c",
+	} {
+		b58, err := blockchain.TronHexToBase58(address)
+		if err != nil {
+			t.Fatalf("Failed to convert hex address %s: %v", address, err)
+		}
+
+		if b58 != "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t" {
+			t.Errorf("Unexpected base58 address %s", b58)
+		}
+	}
+
+	if _, err := blockchain.TronHexToBase58("00a614f803b6fd780986a42c78ec9c7f77e6ded13c"); err == nil {
+		t.Errorf("Converted hex address without tron prefix")
+	}
+}
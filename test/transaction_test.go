@@ -91,3 +91,81 @@ func TestTransactionId(t *testing.T) {
 		}
 	}
 }
+
+func TestTransactionIdNormalisation(t *testing.T) {
+	for _, tc := range []struct {
+		id        string
+		canonical string
+		valid     bool
+	}{{
+		id:        "eip155:1:0x66F2462A072D837B5C4A76DE103A7E5D1CD42C5F77FBD4F95A0DCC9FDDF90B08",
+		canonical: "eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08",
+		valid:     true,
+	}, {
+		// Missing 0x prefix
+		id:    "eip155:1:66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b08",
+		valid: false,
+	}, {
+		// 31 bytes
+		id:    "eip155:1:0x66f2462a072d837b5c4a76de103a7e5d1cd42c5f77fbd4f95a0dcc9fddf90b",
+		valid: false,
+	}, {
+		id:        "bip122:000000000019d6689c085ae165831e93:C55E6D98F3867F5BFFDD3FAE24082BA56A50E81E13C46B67716343A1FEDDA9BA",
+		canonical: "bip122:000000000019d6689c085ae165831e93:c55e6d98f3867f5bffdd3fae24082ba56a50e81e13c46b67716343a1fedda9ba",
+		valid:     true,
+	}, {
+		id:    "bip122:000000000019d6689c085ae165831e93:0xc55e6d98f3867f5bffdd3fae24082ba56a50e81e13c46b67716343a1fedda9ba",
+		valid: false,
+	}, {
+		id:        "cosmos:cosmoshub-3:a57352b805703e81164196d050d9dbac3283304518a421cd0bc4767c143e02ed",
+		canonical: "cosmos:cosmoshub-3:A57352B805703E81164196D050D9DBAC3283304518A421CD0BC4767C143E02ED",
+		valid:     true,
+	}, {
+		id:        "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:3QorZbZ5bRWePAqRsAW5vMLggKfaJQ7RTdKEoXQBsWtgunjrQAN8wrj99yLDqAQassU7DzVYdB62rygzKQd7m7fU",
+		canonical: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:3QorZbZ5bRWePAqRsAW5vMLggKfaJQ7RTdKEoXQBsWtgunjrQAN8wrj99yLDqAQassU7DzVYdB62rygzKQd7m7fU",
+		valid:     true,
+	}, {
+		// 32 byte block hash rather than a 64 byte signature
+		id:    "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7S3P4HxJpyyigGzodYwHtCxZyUQe9JiBMHyRWXArAaKv",
+		valid: false,
+	}, {
+		id:        "tron:0x2b6653dc:0xA5A7F2C4F4E4F8C7A4B0D0C0B6E2E8F2A1C3D5E7F9A1B3C5D7E9F1A3B5C7D9E1",
+		canonical: "tron:0x2b6653dc:a5a7f2c4f4e4f8c7a4b0d0c0b6e2e8f2a1c3d5e7f9a1b3c5d7e9f1a3b5c7d9e1",
+		valid:     true,
+	}} {
+		tt, err := blockchain.ParseTransactionId(tc.id)
+		if !tc.valid {
+			if err == nil {
+				t.Errorf("Parsed invalid transaction id %s", tc.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to parse transaction id %s: %v", tc.id, err)
+		}
+
+		if tt.String() != tc.canonical {
+			t.Errorf("Transaction id not canonical: %s", tt)
+		}
+
+		created, err := blockchain.NewTransactionId(tt.ChainId, tc.id[len(tt.ChainId.String())+1:])
+		if err != nil {
+			t.Fatalf("Failed to create transaction id: %v", err)
+		}
+
+		if created != tt {
+			t.Errorf("Created transaction id not canonical: %s", created)
+		}
+
+		// Struct literals are written in canonical form
+		literal := blockchain.TransactionId{ChainId: tt.ChainId, Hash: tc.id[len(tt.ChainId.String())+1:]}
+		if v, err := literal.Value(); err != nil || v != tc.canonical {
+			t.Errorf("Transaction id value not canonical: %v", v)
+		}
+	}
+
+	literal := blockchain.TransactionId{ChainId: blockchain.MustParseChainId("eip155:1"), Hash: "abc"}
+	if _, err := literal.Value(); err == nil {
+		t.Errorf("Wrote invalid transaction id literal %s", literal)
+	}
+}